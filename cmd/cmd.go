@@ -15,10 +15,14 @@ import (
 var isNotTerminal = os.Getenv("TERM") == "dumb" ||
 	(!isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()))
 
-func init() {
-	if isNotTerminal {
-		cobra.CheckErr(errors.New("not a terminal"))
+// checkTerminal returns an error when the output isn't going into a terminal.
+// The gen hook never prompts and runs without a terminal, e.g. when committing
+// from a GUI client, so it is allowed.
+func checkTerminal() error {
+	if isNotTerminal && genFlags.Hook == "" {
+		return errors.New("not a terminal")
 	}
+	return nil
 }
 
 // getWd is a convenience method to get the working directory.
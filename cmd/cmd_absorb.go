@@ -45,6 +45,7 @@ func absorbAddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&absorbFlags.Backup, "backup", "b", false, "Create a backup branch before rebasing")
 	cmd.Flags().BoolVarP(&absorbFlags.All, "all", "a", false, "Automatically stage all changes in tracked files")
 	cmd.Flags().IntVar(&absorbFlags.MaxHistory, "max-history", 20, "Maximum number of commits to look back in history")
+	addCommitFlags(cmd, &absorbFlags.Commit)
 }
 
 func init() {
@@ -59,6 +60,7 @@ type absorbOptions struct {
 	Backup     bool
 	All        bool
 	MaxHistory int
+	Commit     commitOptions
 }
 
 func absorbSetup(cmd *cobra.Command) (string, error) {
@@ -123,6 +125,10 @@ func runAbsorbE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if absorbFlags.Commit, err = resolveCommitOptions(cmd, absorbFlags.Commit); err != nil {
+		return err
+	}
+
 	if absorbFlags.DryRun {
 		msg := "Running in dry-run mode. No changes will be made."
 		if absorbFlags.All {
@@ -211,7 +217,7 @@ func absorbCreateFixupCommits(workDir string, fixupCommits map[string][]string)
 		}
 
 		prompts.Info(fmt.Sprintf("Creating fixup! commit for %s (%d files)", commitHash[:7], len(files)))
-		if err := gitCreateFixupCommit(workDir, commitHash); err != nil {
+		if err := gitCreateFixupCommit(workDir, commitHash, absorbFlags.Commit); err != nil {
 			return fmt.Errorf("failed to create fixup commit: %w", err)
 		}
 	}
@@ -225,7 +231,7 @@ func absorbHandleRebase(workDir string, fixupCommits map[string][]string) error
 		return fmt.Errorf("failed to find base commit for rebase: %w", err)
 	}
 
-	rebaseCmdString := gitDebugRebaseAutosquash(workDir, baseCommit)
+	rebaseCmdString := gitDebugRebaseAutosquash(workDir, baseCommit, absorbFlags.Commit)
 
 	if absorbFlags.DryRun {
 		promptsx.InfoNoSplitLines("Would run: " + rebaseCmdString)
@@ -283,13 +289,13 @@ func absorbExecuteRebase(workDir, baseCommit, backupBranch string) error {
 		baseCommit = currentBranch
 	}
 
-	if err := gitRebaseAutosquash(workDir, baseCommit); err != nil {
-		rebaseCmdString := gitDebugRebaseAutosquash(workDir, baseCommit)
+	if err := gitRebaseAutosquash(workDir, baseCommit, absorbFlags.Commit); err != nil {
+		rebaseCmdString := gitDebugRebaseAutosquash(workDir, baseCommit, absorbFlags.Commit)
 		promptsx.ErrorNoSplitLines("Rebase failed: " + rebaseCmdString)
 
 		// Provide instructions to restore from backup if one was created
 		if backupBranch != "" {
-			errMsg := fmt.Sprintf("%s Rebase failed. To restore your original branch, run:\n    git checkout -f %s",
+			errMsg := fmt.Sprintf("%s Rebase failed. Resolve the conflicts with 'kai resolve', or to restore your original branch, run:\n    git checkout -f %s",
 				picocolors.Red("✖"), backupBranch)
 			return fmt.Errorf("%s\n%s", err, errMsg)
 		}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/orochaa/go-clack/prompts"
+	"github.com/orochaa/go-clack/third_party/picocolors"
+	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
+
+	"github.com/zbiljic/kai/internal/config"
+	"github.com/zbiljic/kai/pkg/check"
+	"github.com/zbiljic/kai/pkg/gitdiff"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the staged changes for secrets, debug leftovers and conflict markers",
+	Long: `Scans the lines added by the staged changes for content which shouldn't be
+committed, or sent to a model:
+
+  secret           likely secrets, in known key formats or high-entropy tokens
+  conflict-marker  leftover conflict markers
+  debug            leftover debug prints, e.g. console.log or println
+  todo             new TODO, FIXME, XXX and HACK comments
+  large-file       very large files
+
+Secrets and conflict markers are errors, the rest are warnings. The command
+exits with a non-zero status if there are errors, which allows using it as a
+'pre-commit' hook:
+
+  kai check
+
+'kai gen', 'kai prgen' and 'kai prprepare' run the same check before sending
+a diff to the model. With --check, the errors block them, are only reported
+(warn), or the secrets are redacted from the prompt (redact, the default).`,
+	Annotations: map[string]string{"group": "main"},
+	Args:        cobra.NoArgs,
+	RunE:        runCheckE,
+}
+
+// CheckAction represents what is done with the findings of the content check
+// before a diff is sent to the model.
+type CheckAction enumflag.Flag
+
+const (
+	// CheckActionRedact reports the findings and redacts the secrets from the
+	// prompt.
+	CheckActionRedact CheckAction = iota
+	// CheckActionBlock reports the findings and stops on errors.
+	CheckActionBlock
+	// CheckActionWarn only reports the findings.
+	CheckActionWarn
+	// CheckActionOff skips the check.
+	CheckActionOff
+)
+
+// CheckActionIds maps CheckAction to their string representations.
+var CheckActionIds = map[CheckAction][]string{
+	CheckActionRedact: {"redact"},
+	CheckActionBlock:  {"block"},
+	CheckActionWarn:   {"warn"},
+	CheckActionOff:    {"off"},
+}
+
+// CheckOutputFormat represents the output formats of the check command.
+type CheckOutputFormat enumflag.Flag
+
+const (
+	// CheckOutputHuman prints the findings for humans.
+	CheckOutputHuman CheckOutputFormat = iota
+	// CheckOutputJSON prints the findings as JSON.
+	CheckOutputJSON
+	// CheckOutputGitHub prints the findings as GitHub Actions annotations.
+	CheckOutputGitHub
+)
+
+// CheckOutputFormatIds maps CheckOutputFormat to their string representations.
+var CheckOutputFormatIds = map[CheckOutputFormat][]string{
+	CheckOutputHuman:  {"human"},
+	CheckOutputJSON:   {"json"},
+	CheckOutputGitHub: {"github"},
+}
+
+var checkFlags = checkOptions{
+	Output: CheckOutputHuman,
+}
+
+func checkAddFlags(cmd *cobra.Command) {
+	cmd.Flags().VarP(enumflag.New(&checkFlags.Output, "output", CheckOutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format (human, json, github)")
+}
+
+// addCheckFlag adds the flag selecting what is done with the findings of the
+// content check, for the commands sending diffs to the model.
+func addCheckFlag(cmd *cobra.Command, action *CheckAction) {
+	cmd.Flags().Var(enumflag.New(action, "check", CheckActionIds, enumflag.EnumCaseInsensitive), "check", "What to do with secrets and other findings in the diff before sending it (redact, block, warn, off)")
+}
+
+func init() {
+	checkAddFlags(checkCmd)
+
+	rootCmd.AddCommand(checkCmd)
+}
+
+type checkOptions struct {
+	Output CheckOutputFormat
+}
+
+func runCheckE(cmd *cobra.Command, args []string) error {
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return err
+	}
+
+	_, diff, _, err := gitDiffStaged(workDir)
+	if err != nil {
+		return err
+	}
+
+	opts, err := checkLoadOptions()
+	if err != nil {
+		return err
+	}
+
+	findings, err := checkScan(workDir, diff, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPrintFindings(findings, checkFlags.Output); err != nil {
+		return err
+	}
+
+	if check.HasErrors(findings) {
+		return errors.New("The staged changes contain secrets or conflict markers") //nolint:staticcheck
+	}
+
+	return nil
+}
+
+// checkLoadOptions returns the options of the content check from the
+// configuration.
+func checkLoadOptions() (check.Options, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return check.Options{}, err
+	}
+
+	if cfg.Check == nil {
+		return check.Options{}, nil
+	}
+
+	return check.Options{
+		Disable:     cfg.Check.Disable,
+		MaxFileSize: cfg.Check.MaxFileSize,
+	}, nil
+}
+
+// checkResolveAction returns the action of the --check flag when it is set,
+// or else the action of the configuration.
+func checkResolveAction(cmd *cobra.Command, action CheckAction) (CheckAction, error) {
+	if cmd.Flags().Changed("check") {
+		return action, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return action, err
+	}
+
+	if cfg.Check != nil {
+		for a, ids := range CheckActionIds {
+			if cfg.Check.Action == ids[0] {
+				return a, nil
+			}
+		}
+	}
+
+	return CheckActionRedact, nil
+}
+
+// checkScan scans the added lines and the sizes of the files of the diff.
+func checkScan(workDir, diff string, opts check.Options) ([]check.Finding, error) {
+	hunks, err := gitdiff.ParseDiff(diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse diff into hunks: %w", err)
+	}
+
+	return check.Scan(hunks, gitFileChanges(workDir, diff), opts), nil
+}
+
+// checkDiffContent runs the content check on the diff before it is sent to
+// the model, reporting the findings. With the block action, errors stop the
+// command. With the redact action, the returned findings are the secrets to
+// redact from the prompt, e.g. with check.Redact, and nil otherwise.
+func checkDiffContent(workDir, diff string, action CheckAction, interactive bool) ([]check.Finding, error) {
+	opts, err := checkLoadOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	if action == CheckActionOff || strings.TrimSpace(diff) == "" {
+		return nil, nil
+	}
+
+	findings, err := checkScan(workDir, diff, opts)
+	if err != nil || len(findings) == 0 {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(findings))
+	for _, f := range findings {
+		lines = append(lines, f.String())
+	}
+	report := strings.Join(lines, "\n")
+
+	if interactive {
+		prompts.Warn(fmt.Sprintf("The changes contain:\n%s", report))
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: the changes contain:\n%s\n", AppName, report)
+	}
+
+	switch action {
+	case CheckActionBlock:
+		if check.HasErrors(findings) {
+			return nil, errors.New("The changes contain secrets or conflict markers. Remove them, or use --check=redact or --check=warn") //nolint:staticcheck
+		}
+	case CheckActionRedact:
+		return findings, nil
+	}
+
+	return nil, nil
+}
+
+func checkPrintFindings(findings []check.Finding, output CheckOutputFormat) error {
+	switch output {
+	case CheckOutputJSON:
+		if findings == nil {
+			findings = []check.Finding{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(findings)
+	case CheckOutputGitHub:
+		for _, f := range findings {
+			command := "warning"
+			if f.Severity == check.SeverityError {
+				command = "error"
+			}
+			fmt.Printf("::%s file=%s,line=%d,title=%s::%s\n", command, f.File, f.Line, f.Rule, lintEscapeGitHubAnnotation(f.Message))
+		}
+		return nil
+	default:
+		if len(findings) == 0 {
+			fmt.Printf("%s No findings in the staged changes\n", picocolors.Green("✔"))
+			return nil
+		}
+		for _, f := range findings {
+			mark := picocolors.Yellow("⚠")
+			if f.Severity == check.SeverityError {
+				mark = picocolors.Red("✖")
+			}
+			fmt.Printf("%s %s\n", mark, f.String())
+		}
+		return nil
+	}
+}
@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/duke-git/lancet/v2/maputil"
 	"github.com/duke-git/lancet/v2/slice"
@@ -16,20 +20,28 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/thediveo/enumflag/v2"
 
+	"github.com/zbiljic/kai/internal/config"
+	"github.com/zbiljic/kai/pkg/check"
 	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/deps"
+	"github.com/zbiljic/kai/pkg/gitdiff"
+	"github.com/zbiljic/kai/pkg/history"
 	"github.com/zbiljic/kai/pkg/llm"
 	"github.com/zbiljic/kai/pkg/promptsx"
+	"github.com/zbiljic/kai/pkg/scopes"
 	"github.com/zbiljic/kai/pkg/termio"
 )
 
 var genCmd = &cobra.Command{
-	Use: "gen",
+	Use: "gen [<pathspec>...]",
 	Aliases: []string{
 		"g",
 		"generate",
 	},
-	Short:       "Generate commit message",
-	Long:        `Generates commit message based on staged changes. Can optionally include previous commit messages for similar files as examples to maintain consistent style.`,
+	Short: "Generate commit message",
+	Long: `Generates commit message based on staged changes. Can optionally include previous commit messages for similar files as examples to maintain consistent style.
+
+When pathspecs are given, only the changes of those paths are described and committed, leaving other staged changes in the index, like 'git commit -- <pathspec>...'. The staged changes of the paths are used, or their working tree changes with --all.`,
 	Annotations: map[string]string{"group": "main"},
 	Args:        cobra.ArbitraryArgs,
 	RunE:        runGenE,
@@ -41,20 +53,47 @@ var genFlags = genOptions{
 	Model:          "",
 	All:            false,
 	IncludeHistory: true,
+	Verbose:        false,
 	CandidateCount: 2,
 	Yes:            false,
+	Lint:           true,
+	Hook:           "",
+	HookSource:     "",
+	HookTimeout:    15 * time.Second,
+	Print:          false,
+	Output:         GenOutputText,
+	NoStage:        false,
+	Patch:          false,
+	Split:          false,
+	Amend:          false,
+	Check:          CheckActionRedact,
 }
 
 func genAddFlags(cmd *cobra.Command) {
-	cmd.Flags().VarP(enumflag.New(&genFlags.Type, "type", commit.TypeIds, enumflag.EnumCaseInsensitive), "type", "t", "Type of commit message to generate")
+	addCommitTypeFlag(cmd, &genFlags.Type, "Type of commit message to generate")
 	cmd.Flags().BoolVarP(&genFlags.All, "all", "a", false, "Automatically stage all changes in tracked files")
 	cmd.Flags().BoolVar(&genFlags.IncludeHistory, "history", true, "Include previous commit messages as examples")
+	cmd.Flags().BoolVar(&genFlags.Verbose, "verbose", false, "Show the previous commits used as examples")
 	cmd.Flags().IntVarP(&genFlags.CandidateCount, "count", "n", 2, "Number of commit message suggestions to generate")
 	cmd.Flags().BoolVarP(&genFlags.Yes, "yes", "y", false, "Run in non-interactive mode, automatically using the first generated commit message")
+	cmd.Flags().BoolVar(&genFlags.Lint, "lint", true, "Validate generated commit messages and ask the model to repair violations")
+	cmd.Flags().StringVar(&genFlags.Hook, "hook", "", "Run as a prepare-commit-msg hook, writing the generated message into the given file")
+	cmd.Flags().StringVar(&genFlags.HookSource, "hook-source", "", "Source of the commit message passed to the prepare-commit-msg hook")
+	cmd.Flags().DurationVar(&genFlags.HookTimeout, "hook-timeout", 15*time.Second, "Maximum time to spend generating the message in hook mode")
+	cmd.Flags().MarkHidden("hook-source") //nolint:errcheck
+	cmd.Flags().BoolVar(&genFlags.Print, "print", false, "Print the generated commit message instead of committing")
+	cmd.Flags().VarP(enumflag.New(&genFlags.Output, "output", GenOutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format of --print (text, json)")
+	cmd.Flags().BoolVar(&genFlags.Patch, "patch", false, "Interactively select the hunks of the working tree changes to stage and commit")
+	cmd.Flags().BoolVar(&genFlags.Split, "split", false, "Propose splitting the staged changes into multiple commits")
+	cmd.Flags().BoolVar(&genFlags.Amend, "amend", false, "Regenerate the message of the HEAD commit and amend it with the staged changes")
+	cmd.Flags().BoolVar(&genFlags.NoStage, "no-stage", false, "Don't stage any changes, use the working tree changes if nothing is staged (requires --print)")
 }
 
 func init() {
 	addCommonLLMFlags(genCmd, &genFlags.Provider, &genFlags.Model)
+	addCheckFlag(genCmd, &genFlags.Check)
+	addLangFlag(genCmd, &genFlags.Lang)
+	addCommitFlags(genCmd, &genFlags.Commit)
 	genAddFlags(genCmd)
 
 	rootCmd.AddCommand(genCmd)
@@ -66,8 +105,38 @@ type genOptions struct {
 	Model          string
 	All            bool
 	IncludeHistory bool
+	Verbose        bool
 	CandidateCount int
 	Yes            bool
+	Lint           bool
+	Hook           string
+	HookSource     string
+	HookTimeout    time.Duration
+	Print          bool
+	Output         GenOutputFormat
+	NoStage        bool
+	Patch          bool
+	Split          bool
+	Amend          bool
+	Check          CheckAction
+	Lang           string
+	Commit         commitOptions
+}
+
+// GenOutputFormat represents the output formats of the printed messages.
+type GenOutputFormat enumflag.Flag
+
+const (
+	// GenOutputText prints the commit message.
+	GenOutputText GenOutputFormat = iota
+	// GenOutputJSON prints all candidates with their details as JSON.
+	GenOutputJSON
+)
+
+// GenOutputFormatIds maps GenOutputFormat to their string representations.
+var GenOutputFormatIds = map[GenOutputFormat][]string{
+	GenOutputText: {"text"},
+	GenOutputJSON: {"json"},
 }
 
 // genSetupCommandClackIntro sets up clack intro and injects into command context
@@ -84,7 +153,7 @@ func genSetup(cmd *cobra.Command) (string, error) {
 	return setupGitWorkDir()
 }
 
-func genDetectAndStageFiles(workDir string, all bool) ([]string, string, error) {
+func genDetectAndStageFiles(workDir string, all bool) ([]string, string, []gitdiff.FileStat, error) {
 	var detectingFilesSpinner *prompts.SpinnerController
 	if !genFlags.Yes {
 		detectingFilesSpinner = prompts.Spinner(prompts.SpinnerOptions{})
@@ -92,16 +161,35 @@ func genDetectAndStageFiles(workDir string, all bool) ([]string, string, error)
 	}
 
 	// Check for staged files first
-	files, diff, err := gitDiffStaged(workDir)
+	files, diff, excluded, err := gitDiffStaged(workDir)
 	if err != nil {
 		if !genFlags.Yes && detectingFilesSpinner != nil {
 			detectingFilesSpinner.Stop("Error detecting staged files", 1)
 		}
-		return nil, "", err
+		return nil, "", nil, err
 	}
 
-	// If no files are staged, automatically set All flag to true
-	if len(files) == 0 {
+	// Without staging, describe the changes in the working tree instead
+	if len(files) == 0 && len(excluded) == 0 && genFlags.NoStage {
+		files, diff, excluded, err = gitDiffUnstaged(workDir)
+		if err != nil {
+			if !genFlags.Yes && detectingFilesSpinner != nil {
+				detectingFilesSpinner.Stop("Error detecting changed files", 1)
+			}
+			return nil, "", nil, err
+		}
+
+		if len(files) == 0 && len(excluded) == 0 {
+			if !genFlags.Yes && detectingFilesSpinner != nil {
+				detectingFilesSpinner.Stop("No changes detected", 0)
+			}
+			return nil, "", nil, errors.New("No changes detected") //nolint:staticcheck
+		}
+	}
+
+	// If no files are staged, automatically set All flag to true. Files left
+	// out of the diff, e.g. lockfiles, are still staged changes.
+	if len(files) == 0 && len(excluded) == 0 {
 		all = true
 	}
 
@@ -110,123 +198,548 @@ func genDetectAndStageFiles(workDir string, all bool) ([]string, string, error)
 			if !genFlags.Yes && detectingFilesSpinner != nil {
 				detectingFilesSpinner.Stop("Error staging files", 1)
 			}
-			return nil, "", err
+			return nil, "", nil, err
 		}
 
 		// Get updated list of staged files after adding all
-		files, diff, err = gitDiffStaged(workDir)
+		files, diff, excluded, err = gitDiffStaged(workDir)
 		if err != nil {
 			if !genFlags.Yes && detectingFilesSpinner != nil {
 				detectingFilesSpinner.Stop("Error detecting staged files", 1)
 			}
-			return nil, "", err
+			return nil, "", nil, err
 		}
 
-		if len(files) == 0 {
+		if len(files) == 0 && len(excluded) == 0 {
 			if !genFlags.Yes && detectingFilesSpinner != nil {
 				detectingFilesSpinner.Stop("No changes detected to stage", 0)
 			}
-			return nil, "", errors.New("No changes detected to stage") //nolint:staticcheck
+			return nil, "", nil, errors.New("No changes detected to stage") //nolint:staticcheck
 		}
 	}
 
+	detectedFiles := append([]string{}, files...)
+	for _, stat := range excluded {
+		detectedFiles = append(detectedFiles, stat.Path+picocolors.Dim(" (left out of the diff)"))
+	}
+
 	detectedMessage := fmt.Sprintf(
 		"Detected %d staged file(s):\n     %s",
-		len(files),
-		strings.Join(files, "\n     "),
+		len(detectedFiles),
+		strings.Join(detectedFiles, "\n     "),
 	)
 
 	if !genFlags.Yes && detectingFilesSpinner != nil {
 		detectingFilesSpinner.Stop(detectedMessage, 0)
 	}
-	return files, diff, nil
+	return files, diff, excluded, nil
+}
+
+// genDetectAmendChanges returns the diff of the HEAD commit together with the
+// staged changes, and the message of the HEAD commit. Amending a commit which
+// is already on the upstream branch is refused in non-interactive mode, and
+// has to be confirmed otherwise.
+func genDetectAmendChanges(workDir string, all, printOnly bool) (string, string, []gitdiff.FileStat, error) {
+	if !printOnly {
+		upstream, pushed := gitHeadOnUpstream(workDir)
+		if pushed {
+			if genFlags.Yes {
+				return "", "", nil, fmt.Errorf("HEAD is already on the upstream branch %s, amending it would rewrite published history", upstream)
+			}
+
+			confirmed, err := prompts.Confirm(prompts.ConfirmParams{
+				Message: fmt.Sprintf("HEAD is already on the upstream branch %s. Amend it anyway?", upstream),
+			})
+			if err != nil {
+				return "", "", nil, err
+			}
+			if !confirmed {
+				return "", "", nil, errors.New("Amend cancelled") //nolint:staticcheck
+			}
+		}
+	}
+
+	if all {
+		if err := gitAddAll(workDir); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	commits, err := gitCommitMessages(workDir, "HEAD")
+	if err != nil {
+		return "", "", nil, err
+	}
+	if len(commits) == 0 {
+		return "", "", nil, errors.New("There is no commit to amend") //nolint:staticcheck
+	}
+
+	_, diff, excluded, err := gitDiffAmend(workDir, commits[0].Parents)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if diff == "" && len(excluded) == 0 {
+		return "", "", nil, errors.New("No changes detected in the commit to amend") //nolint:staticcheck
+	}
+
+	if !genFlags.Yes {
+		promptsx.Note(fmt.Sprintf("Amending: %s", commit.Header(commits[0].Message)))
+	}
+
+	return diff, commits[0].Message, excluded, nil
 }
 
-// genGetPreviousCommitsForStagedFiles returns previous commit messages for all
-// staged files. For new files without history, it tries to get commit history
-// from their parent directories.
-func genGetPreviousCommitsForStagedFiles(workDir string) ([]string, error) {
-	stagedFiles, err := gitStagedFiles(workDir)
+// genDetectPathspecFiles returns the files and diff of the changes to commit
+// for the pathspecs, without staging them. Without all, the staged changes of
+// the pathspecs are used, and they must not have unstaged changes or untracked
+// files, since the working tree contents of the pathspecs are committed. With
+// all, the untracked files of the pathspecs are added with the intent to add
+// them, so they are committed too.
+func genDetectPathspecFiles(workDir string, all bool, pathspecs []string) ([]string, string, []gitdiff.FileStat, error) {
+	var detectingFilesSpinner *prompts.SpinnerController
+	if !genFlags.Yes {
+		detectingFilesSpinner = prompts.Spinner(prompts.SpinnerOptions{})
+		detectingFilesSpinner.Start("Detecting changed files")
+	}
+
+	stopSpinner := func(msg string, code int) {
+		if !genFlags.Yes && detectingFilesSpinner != nil {
+			detectingFilesSpinner.Stop(msg, code)
+		}
+	}
+
+	untracked, err := gitUntrackedFiles(workDir, pathspecs)
 	if err != nil {
-		return nil, err
+		stopSpinner("Error detecting changed files", 1)
+		return nil, "", nil, err
 	}
 
-	// If no staged files, return empty slice
-	if len(stagedFiles) == 0 {
-		return []string{}, nil
+	if len(untracked) > 0 {
+		switch {
+		case !all:
+			stopSpinner("Untracked files detected", 1)
+			return nil, "", nil, fmt.Errorf("The given paths have untracked files, add them or use --all: %s", strings.Join(untracked, ", ")) //nolint:staticcheck
+		case genFlags.NoStage:
+			stopSpinner("Untracked files detected", 1)
+			return nil, "", nil, fmt.Errorf("The given paths have untracked files, which can't be described with --no-stage: %s", strings.Join(untracked, ", ")) //nolint:staticcheck
+		}
+
+		if err := gitIntentToAdd(workDir, untracked); err != nil {
+			stopSpinner("Error detecting changed files", 1)
+			return nil, "", nil, err
+		}
+	}
+
+	var (
+		files    []string
+		diff     string
+		excluded []gitdiff.FileStat
+	)
+
+	if all {
+		files, diff, excluded, err = gitDiffWorkingTreePaths(workDir, pathspecs)
+	} else {
+		files, diff, excluded, err = gitDiffStagedPaths(workDir, pathspecs)
+	}
+	if err != nil {
+		stopSpinner("Error detecting changed files", 1)
+		return nil, "", nil, err
 	}
 
-	// Get previous commit messages for staged files
-	allMessages := make(map[string]struct{})
-	filesWithoutHistory := []string{}
+	if len(files) == 0 && len(excluded) == 0 {
+		stopSpinner("No changes detected", 0)
+		if all {
+			return nil, "", nil, errors.New("No changes detected in the given paths") //nolint:staticcheck
+		}
+		return nil, "", nil, errors.New("No staged changes in the given paths. Use --all to commit their working tree changes") //nolint:staticcheck
+	}
 
-	for _, file := range stagedFiles {
-		fileMessages, err := gitPreviousCommitMessages(workDir, []string{file}, llm.DefaultMaxCommitsPerFile)
+	if !all && !genFlags.NoStage {
+		unstaged, _, unstagedExcluded, err := gitDiffUnstagedPaths(workDir, pathspecs)
 		if err != nil {
-			continue // Skip files with errors
+			stopSpinner("Error detecting changed files", 1)
+			return nil, "", nil, err
+		}
+		for _, stat := range unstagedExcluded {
+			unstaged = append(unstaged, stat.Path)
 		}
 
-		// If no history for this file, remember it for directory fallback
-		if len(fileMessages) == 0 {
-			filesWithoutHistory = append(filesWithoutHistory, file)
-		} else {
-			// Add messages to the deduplicated set
-			for _, msg := range fileMessages {
-				allMessages[msg] = struct{}{}
-			}
+		if len(unstaged) > 0 {
+			stopSpinner("Unstaged changes detected", 1)
+			return nil, "", nil, fmt.Errorf("The given paths have unstaged changes, stage them or use --all: %s", strings.Join(unstaged, ", ")) //nolint:staticcheck
 		}
+	}
+
+	detectedFiles := append([]string{}, files...)
+	for _, stat := range excluded {
+		detectedFiles = append(detectedFiles, stat.Path+picocolors.Dim(" (left out of the diff)"))
+	}
 
-		// Limit the total number of messages
-		if len(allMessages) >= llm.DefaultMaxTotalCommits {
-			break
+	stopSpinner(fmt.Sprintf(
+		"Detected %d changed file(s):\n     %s",
+		len(detectedFiles),
+		strings.Join(detectedFiles, "\n     "),
+	), 0)
+
+	return files, diff, excluded, nil
+}
+
+// genPatchStageHunks lets the user select hunks of the unstaged changes, like
+// 'git add --patch', and stages them. The pathspecs limit the listed hunks.
+// The untracked files of the pathspecs are listed too, added with the intent
+// to add them; the ones without selected hunks are left untracked again.
+func genPatchStageHunks(workDir string, pathspecs []string) (err error) {
+	var untracked []string
+	if len(pathspecs) > 0 {
+		if untracked, err = gitUntrackedFiles(workDir, pathspecs); err != nil {
+			return err
 		}
 	}
 
-	// For files without history, try to get commit history from their directories
-	if len(filesWithoutHistory) > 0 && len(allMessages) < llm.DefaultMaxTotalCommits {
-		// Get unique directories from files without history
-		dirSet := make(map[string]struct{})
-		for _, file := range filesWithoutHistory {
-			dir := filepath.Dir(file)
-			// Skip root directory
-			if dir != "." && dir != "/" {
-				dirSet[dir] = struct{}{}
+	if len(untracked) > 0 {
+		if err := gitIntentToAdd(workDir, untracked); err != nil {
+			return err
+		}
+
+		defer func() {
+			// the files of the selected hunks are staged now
+			stagedPaths, _ := gitStagedPaths(workDir)
+
+			var unselected []string
+			for _, file := range untracked {
+				if err != nil || !slice.Contain(stagedPaths, file) {
+					unselected = append(unselected, file)
+				}
+			}
+			if len(unselected) == 0 {
+				return
 			}
+			if resetErr := gitUnstageFiles(workDir, unselected); resetErr != nil && err == nil {
+				err = resetErr
+			}
+		}()
+	}
+
+	_, diff, _, err := gitDiffUnstagedPaths(workDir, pathspecs)
+	if err != nil {
+		return err
+	}
+
+	if diff == "" {
+		return errors.New("No unstaged changes to select hunks from") //nolint:staticcheck
+	}
+
+	hunks, err := gitdiff.ParseDiff(diff + "\n")
+	if err != nil {
+		return fmt.Errorf("failed to parse diff into hunks: %w", err)
+	}
+
+	var (
+		files     []string
+		options   = make(map[string][]prompts.MultiSelectOption[string])
+		hunksByID = make(map[string]*gitdiff.Hunk, len(hunks))
+	)
+
+	for _, hunk := range hunks {
+		if _, ok := options[hunk.FilePath]; !ok {
+			files = append(files, hunk.FilePath)
+		}
+
+		header, _, _ := strings.Cut(hunk.Content, "\n")
+		additions, deletions := genCountHunkChanges(hunk.Content)
+
+		options[hunk.FilePath] = append(options[hunk.FilePath], prompts.MultiSelectOption[string]{
+			Label: header,
+			Value: hunk.ID,
+			Hint:  fmt.Sprintf("+%d −%d", additions, deletions),
+		})
+		hunksByID[hunk.ID] = hunk
+	}
+
+	if len(hunks) == 0 {
+		return errors.New("No hunks found in the unstaged changes") //nolint:staticcheck
+	}
+
+	hunkIDs, err := promptsx.GroupMultiSelectPreview(promptsx.GroupMultiSelectPreviewParams[string]{
+		Message:  "Select the hunks to commit",
+		Groups:   files,
+		Options:  options,
+		Required: true,
+		Preview: func(id string) string {
+			return genFormatHunkPreview(hunksByID[id].Content)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := gitdiff.StageHunks(workDir, hunkIDs, hunksByID, diff+"\n"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// genCountHunkChanges returns the number of added and removed lines of the hunk.
+func genCountHunkChanges(content string) (int, int) {
+	var additions, deletions int
+	for _, line := range strings.Split(content, "\n")[1:] {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
 		}
+	}
+	return additions, deletions
+}
 
-		// Convert to slice
-		var directories []string
-		for dir := range dirSet {
-			directories = append(directories, dir)
+// genFormatHunkPreview colors the lines of the hunk, without its header.
+func genFormatHunkPreview(content string) string {
+	lines := strings.Split(content, "\n")[1:]
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			lines[i] = picocolors.Green(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = picocolors.Red(line)
+		default:
+			lines[i] = picocolors.Dim(line)
 		}
+	}
+	return strings.Join(lines, "\n")
+}
 
-		if len(directories) > 0 {
-			remainingCommits := llm.DefaultMaxTotalCommits - len(allMessages)
-			dirMessages, err := gitPreviousCommitMessagesForDirectories(workDir, directories, remainingCommits)
-			if err == nil {
-				for _, msg := range dirMessages {
-					allMessages[msg] = struct{}{}
-					if len(allMessages) >= llm.DefaultMaxTotalCommits {
-						break
-					}
-				}
+// genRevertMaxCommits is the number of recent commits of the changed files
+// checked for being reverted by the changes.
+const genRevertMaxCommits = 20
+
+// genDetectRevert returns the revert message when the diff reverts one of the
+// recent commits of the files. In interactive mode the user can decline it,
+// and have the message generated instead.
+func genDetectRevert(workDir string, files []string, diff string) (string, error) {
+	commits, err := gitCommitsTouchingFiles(workDir, files, genRevertMaxCommits)
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			continue
+		}
+
+		parent := gitEmptyTree
+		if len(c.Parents) > 0 {
+			parent = c.Parents[0]
+		}
+
+		_, commitDiff, _, err := gitDiffChanges(workDir, false, parent+".."+c.Hash, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to get diff of commit %s: %w", c.Hash[:7], err)
+		}
+
+		if !gitdiff.IsRevert(diff, commitDiff) {
+			continue
+		}
+
+		header := commit.Header(c.Message)
+
+		if !genFlags.Yes {
+			confirmed, err := prompts.Confirm(prompts.ConfirmParams{
+				Message: fmt.Sprintf("The changes revert %s %q. Use a revert message?", c.Hash[:7], header),
+			})
+			if err != nil {
+				return "", err
+			}
+			if !confirmed {
+				return "", nil
 			}
 		}
+
+		return commit.RevertMessage(genFlags.Type, c.Hash, header, ""), nil
+	}
+
+	return "", nil
+}
+
+// genDetectDependencyChanges returns the dependency changes of the manifests
+// among the files, and the message describing them when the changes consist
+// only of dependencies and lockfiles. The new content of the manifests is read
+// from the working tree instead of the index when worktree is true.
+func genDetectDependencyChanges(workDir string, files []string, excluded []gitdiff.FileStat, worktree bool) ([]deps.Change, string, error) {
+	var (
+		changes   []deps.Change
+		lockfiles []string
+	)
+	onlyDependencies := len(files) > 0 || len(excluded) > 0
+
+	// other files left out of the diff, e.g. generated code, aren't lockfiles
+	lockfilePatterns := gitdiff.NewIgnore(filesToExclude...)
+	for _, stat := range excluded {
+		if !deps.IsLockfile(stat.Path) && !lockfilePatterns.Match(stat.Path) {
+			onlyDependencies = false
+			continue
+		}
+		lockfiles = append(lockfiles, stat.Path)
 	}
 
-	// Convert map to slice
-	var result []string
-	for msg := range allMessages {
-		result = append(result, msg)
-		if len(result) >= llm.DefaultMaxTotalCommits {
-			break
+	for _, file := range files {
+		if !deps.IsManifest(file) {
+			onlyDependencies = false
+			continue
+		}
+
+		oldContent := gitFileContent(workDir, "HEAD", file)
+		newContent := gitFileContent(workDir, "", file)
+		if worktree {
+			content, err := os.ReadFile(filepath.Join(workDir, file))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, "", fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			newContent = string(content)
+		}
+
+		fileChanges := deps.Diff(file, oldContent, newContent)
+		if len(fileChanges) == 0 || !deps.OnlyDependencies(oldContent, newContent, fileChanges) {
+			onlyDependencies = false
 		}
+		changes = append(changes, fileChanges...)
+	}
+
+	if !onlyDependencies {
+		return changes, "", nil
+	}
+
+	return changes, deps.Message(genFlags.Type, changes, lockfiles), nil
+}
+
+// genPromptContext returns the prompt sections describing what the raw diff
+// doesn't show well: the summary of the changed files, the dependency changes
+// and the files left out of the diff, and the scopes for the changes.
+func genPromptContext(workDir, diff string, changes []deps.Change, excluded []gitdiff.FileStat, scopeSuggestions []scopes.Suggestion) []string {
+	var promptContext []string
+	if section := llm.ChangeSummarySection(gitFileChanges(workDir, diff)); section != "" {
+		promptContext = append(promptContext, section)
+	}
+	if len(changes) > 0 {
+		promptContext = append(promptContext, fmt.Sprintf(llm.PromptDependencyChangesFormat, deps.Summary(changes)))
+	}
+	if section := llm.ExcludedFilesSection(excluded); section != "" {
+		promptContext = append(promptContext, section)
+	}
+	if section := llm.ScopesSection(scopeSuggestions); section != "" {
+		promptContext = append(promptContext, section)
+	}
+	return promptContext
+}
+
+// genScopeSuggestions returns the scopes for the files changed by the diff,
+// from the scope rules of the configuration and the scopes of the history.
+// Only conventional commit messages have scopes to suggest.
+func genScopeSuggestions(workDir, diff string) ([]scopes.Suggestion, error) {
+	if genFlags.Type != commit.ConventionalType {
+		return nil, nil
+	}
+
+	var files []string
+	for _, change := range gitdiff.ParseFileChanges(diff) {
+		files = append(files, change.Path)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]scopes.Rule, 0, len(cfg.ScopeRules))
+	for _, rule := range cfg.ScopeRules {
+		rules = append(rules, scopes.Rule{Path: rule.Path, Scope: rule.Scope})
+	}
+
+	// without history, e.g. in a repository without commits, only the rules
+	// are used
+	commits, _ := gitHistoryCommits(workDir, []string{"."}, llm.DefaultMaxScopeCommits)
+
+	return scopes.Suggest(scopes.Learn(commits), rules, files), nil
+}
+
+// genGetPreviousCommits returns the subjects of the previous commits most
+// relevant to the files changed by the diff, as examples of the style of the
+// repository. They are retrieved in a single pass over the history of the
+// directories of the files and ranked by history.Rank, so the examples, and
+// their order, are the same between runs.
+func genGetPreviousCommits(workDir, diff string) ([]string, error) {
+	var files, paths []string
+	for _, change := range gitdiff.ParseFileChanges(diff) {
+		files = append(files, change.Path)
+		// the directories cover new files, and files at the root of the
+		// repository are compared with the whole history
+		paths = append(paths, filepath.Dir(change.Path))
+	}
+
+	if len(files) == 0 {
+		return []string{}, nil
+	}
+
+	commits, err := gitHistoryCommits(workDir, slice.Unique(paths), llm.DefaultMaxHistoryCommits)
+	if err != nil {
+		// e.g. a repository without commits yet
+		return []string{}, nil
+	}
+
+	examples := history.Rank(commits, files, gitUserEmail(workDir), time.Now(), llm.DefaultMaxTotalCommits)
+
+	if genFlags.Verbose {
+		genShowExamples(examples)
+	}
+
+	result := make([]string, 0, len(examples))
+	for _, example := range examples {
+		result = append(result, example.Subject)
 	}
 
 	return result, nil
 }
 
-func genMessages(ctx context.Context, aip llm.AIPrompt, commitType commit.Type, workDir, diff string) ([]string, error) {
+// genShowExamples shows the history examples used in the prompt, with their
+// scores. Without the interactive UI they are written to stderr, so the
+// printed message stays the only output.
+func genShowExamples(examples []history.Example) {
+	var sb strings.Builder
+	for _, example := range examples {
+		fmt.Fprintf(&sb, "%.2f %s %s\n", example.Score, example.Hash[:7], example.Subject)
+	}
+
+	summary := strings.TrimSuffix(sb.String(), "\n")
+	if summary == "" {
+		summary = "no relevant commits found"
+	}
+
+	if genFlags.Yes {
+		fmt.Fprintf(os.Stderr, "History examples:\n%s\n", summary)
+		return
+	}
+
+	promptsx.Note("History examples:\n" + summary)
+}
+
+// genMessages generates the commit messages for the diff. The prompt context
+// sections, e.g. the dependency changes, are added to the prompt.
+func genMessages(ctx context.Context, aip llm.AIPrompt, commitType commit.Type, workDir, diff, amendMessage string, promptContext ...string) ([]string, error) {
+	// the history examples are retrieved before the spinner starts, so they
+	// can be shown with --verbose
+	var previousCommits []string
+	if genFlags.IncludeHistory {
+		var err error
+		previousCommits, err = genGetPreviousCommits(workDir, diff)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var generateMessageSpinner *prompts.SpinnerController
 	if !genFlags.Yes {
 		generateMessageSpinner = prompts.Spinner(prompts.SpinnerOptions{})
@@ -238,32 +751,74 @@ func genMessages(ctx context.Context, aip llm.AIPrompt, commitType commit.Type,
 	var err error
 
 	// Decide whether to include commit history based on the flag
-	if genFlags.IncludeHistory {
-		var previousCommits []string
-		previousCommits, err = genGetPreviousCommitsForStagedFiles(workDir)
-		if err == nil {
-			messages, err = llm.GenerateCommitMessageWithPreviousCommits(ctx, aip, commitType, workDir, diff, previousCommits, genFlags.CandidateCount)
-		}
-	} else {
-		messages, err = llm.GenerateCommitMessage(ctx, aip, commitType, diff, genFlags.CandidateCount)
+	switch {
+	case amendMessage != "":
+		messages, err = llm.GenerateAmendCommitMessage(ctx, aip, commitType, genFlags.Lang, diff, amendMessage, previousCommits, genFlags.CandidateCount, promptContext...)
+	case genFlags.IncludeHistory:
+		messages, err = llm.GenerateCommitMessageWithPreviousCommits(ctx, aip, commitType, genFlags.Lang, workDir, diff, previousCommits, genFlags.CandidateCount, promptContext...)
+	default:
+		messages, err = llm.GenerateCommitMessage(ctx, aip, commitType, genFlags.Lang, diff, genFlags.CandidateCount, promptContext...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := loadCommitRules(workDir, commitType)
+	if err != nil {
+		return nil, err
 	}
 
+	messages, err = filterAndProcessMessages(messages, commitType, rules)
 	if err != nil {
 		return nil, err
 	}
 
+	var violations map[string][]commit.Violation
+	if genFlags.Lint {
+		if generateMessageSpinner != nil {
+			generateMessageSpinner.Message("Validating commit messages")
+		}
+		messages, violations = genRepairMessages(ctx, aip, commitType, genFlags.Lang, rules, diff, messages)
+	}
+
+	// the provider is unreachable, the messages come from the heuristics
+	fellBack := false
+	if fallback, ok := aip.(*llm.Fallback); ok && fallback.FellBack() {
+		fellBack = true
+		if genFlags.Yes {
+			fmt.Fprintf(os.Stderr, "%s: provider unreachable, commit message generated offline\n", AppName)
+		}
+	}
+
 	if !genFlags.Yes && generateMessageSpinner != nil {
 		generateMessageSpinner.Stop("Changes analyzed", 0)
+
+		if fellBack {
+			prompts.Warn("The provider is unreachable, the commit messages are generated offline")
+		}
+
+		for _, message := range messages {
+			if len(violations[message]) > 0 {
+				prompts.Warn(fmt.Sprintf("%s\n%s", message, genFormatViolations(violations[message])))
+			}
+		}
+
 		// Clear any pending input from stdin immediately after stopping the spinner
 		// This prevents buffered keystrokes (like Enter) from being consumed by the selection prompt
 		termio.ClearStdinBuffer()
 	}
 
-	return filterAndProcessMessages(messages)
+	return messages, nil
 }
 
 // filterAndProcessMessages removes empty messages and formats them properly
-func filterAndProcessMessages(messages []string) ([]string, error) {
+func filterAndProcessMessages(messages []string, commitType commit.Type, rules commit.Rules) ([]string, error) {
+	// sanitize and repair what can be repaired without the model
+	messages = slice.Map(messages, func(_ int, s string) string {
+		return rules.Fix(commitType, s)
+	})
+
 	// remove empty messages
 	messages = slice.Filter(messages, func(_ int, s string) bool {
 		return strutil.IsNotBlank(s)
@@ -273,22 +828,83 @@ func filterAndProcessMessages(messages []string) ([]string, error) {
 		return nil, errors.New("No commit messages were generated. Try again.") //nolint:staticcheck
 	}
 
-	// lowercase the first letter of commit message
-	return slice.Map(messages, func(_ int, s string) string {
-		m := commit.ParseMessage(s)
-		m.CommitMessage = strutil.LowerFirst(m.CommitMessage)
-		return m.ToString()
-	}), nil
+	// user-defined formats are rendered as configured
+	if _, ok := commitType.CustomFormat(); ok {
+		return slice.Unique(messages), nil
+	}
+
+	// lowercase the first letter of the commit message in the header, keeping
+	// the body as it is
+	return slice.Unique(slice.Map(messages, func(_ int, s string) string {
+		header, body, hasBody := strings.Cut(s, "\n")
+		m := commit.ParseMessage(header)
+		if !commit.StartsWithAcronym(m.CommitMessage) {
+			m.CommitMessage = strutil.LowerFirst(m.CommitMessage)
+		}
+		if !hasBody {
+			return m.ToString()
+		}
+		return m.ToString() + "\n" + body
+	})), nil
+}
+
+// genRepairMessages validates the messages against the rules, and re-prompts
+// the model with the violations of the messages that aren't valid. Messages
+// that are valid are moved before the ones that still have violations, which
+// are returned by message.
+func genRepairMessages(
+	ctx context.Context,
+	aip llm.AIPrompt,
+	commitType commit.Type,
+	lang string,
+	rules commit.Rules,
+	diff string,
+	messages []string,
+) ([]string, map[string][]commit.Violation) {
+	var (
+		valid      []string
+		invalid    []string
+		violations = make(map[string][]commit.Violation)
+	)
+
+	for _, message := range messages {
+		messageViolations := rules.Lint(commitType, message)
+		if len(messageViolations) > 0 {
+			repaired, err := llm.RepairCommitMessage(ctx, aip, commitType, lang, rules.MaxLength(), diff, message, messageViolations)
+			if err == nil && strutil.IsNotBlank(repaired) {
+				repaired = rules.Fix(commitType, repaired)
+				if repairedViolations := rules.Lint(commitType, repaired); len(repairedViolations) < len(messageViolations) {
+					message, messageViolations = repaired, repairedViolations
+				}
+			}
+		}
+
+		if len(messageViolations) == 0 {
+			valid = append(valid, message)
+		} else {
+			invalid = append(invalid, message)
+			violations[message] = messageViolations
+		}
+	}
+
+	return slice.Unique(append(valid, invalid...)), violations
 }
 
-func genHandleMessageSelection(messages []string) (string, error) {
+// genFormatViolations formats the rule violations for display.
+func genFormatViolations(violations []commit.Violation) string {
+	return strings.Join(slice.Map(violations, func(_ int, v commit.Violation) string {
+		return "  ✖ " + v.String()
+	}), "\n")
+}
+
+func genHandleMessageSelection(workDir, diff string, messages []string, scopeSuggestions []scopes.Suggestion) (string, error) {
 	for {
 		selected, err := promptsx.SelectEdit(promptsx.SelectEditParams[string]{
 			Message: fmt.Sprintf("Pick a commit message to use: %s", picocolors.Gray("(Ctrl+c to exit)")),
 			Options: slice.FlatMap(messages, func(i int, s string) []promptsx.SelectEditOption[string] {
 				return []promptsx.SelectEditOption[string]{{Label: s, Key: fmt.Sprintf("%d", i+1)}}
 			}),
-			EditHint: "e to edit",
+			EditHint: "e to edit, E to edit in $EDITOR",
 		})
 		if err != nil {
 			if prompts.IsCancel(err) {
@@ -300,11 +916,28 @@ func genHandleMessageSelection(messages []string) (string, error) {
 
 		message := selected.Value
 
-		if !selected.Edit {
-			return message, nil
+		if selected.Editor {
+			editedMessage, err := genEditCommitMessageInEditor(workDir, diff, message)
+			if err != nil {
+				return "", err
+			}
+
+			if editedMessage == "" {
+				prompts.Warn("The edited commit message is empty, keeping the previous one")
+				continue
+			}
+
+			// the edited message replaces the selected one, first so it is
+			// preselected, and the other candidates can still be picked
+			messages = append([]string{editedMessage}, slice.Filter(messages, func(_ int, m string) bool { return m != message })...)
+			continue
 		}
 
-		editedMessage, err := genEditCommitMessage(message, genFlags.Type)
+		if !selected.Edit {
+			return message, nil
+		}
+
+		editedMessage, err := genEditCommitMessage(message, genFlags.Type, scopeSuggestions)
 		if err != nil {
 			if prompts.IsCancel(err) {
 				prompts.Outro("Commit cancelled")
@@ -317,15 +950,78 @@ func genHandleMessageSelection(messages []string) (string, error) {
 	}
 }
 
-func genEditCommitMessage(message string, commitType commit.Type) (string, error) {
+// genEditCommitMessageInEditor edits the whole message, with its body and
+// trailers, in the user's editor. The rules and the summary of the changes are
+// added as comments, which are stripped like git does. The edited message is
+// validated against the rules again, and the violations are shown.
+func genEditCommitMessageInEditor(workDir, diff, message string) (string, error) {
+	rules, err := loadCommitRules(workDir, genFlags.Type)
+	if err != nil {
+		return "", err
+	}
+
+	commentChar := gitCommentChar(workDir)
+
+	var content strings.Builder
+	content.WriteString(message + "\n\n")
+	content.WriteString(commentChar + " Edit the commit message. Lines starting with '" + commentChar + "' are ignored, and an empty\n")
+	content.WriteString(commentChar + " message keeps the previous one.\n")
+
+	if descriptions := rules.Describe(genFlags.Type); len(descriptions) > 0 {
+		content.WriteString(commentChar + "\n" + commentChar + " Rules:\n")
+		for _, description := range descriptions {
+			content.WriteString(commentChar + "   " + description + "\n")
+		}
+	}
+
+	if summary := gitdiff.FormatChangeSummary(gitFileChanges(workDir, diff)); summary != "" {
+		content.WriteString(commentChar + "\n" + commentChar + " Changes:\n")
+		for _, line := range strings.Split(strings.TrimRight(summary, "\n"), "\n") {
+			content.WriteString(commentChar + "   " + line + "\n")
+		}
+	}
+
+	edited, err := promptsx.EditInEditor(content.String(), "kai-COMMIT_EDITMSG-*")
+	if err != nil {
+		return "", err
+	}
+
+	edited = commit.StripComments(edited, commentChar)
+	if edited == "" {
+		return "", nil
+	}
+
+	if violations := rules.Lint(genFlags.Type, edited); len(violations) > 0 {
+		prompts.Warn(fmt.Sprintf("%s\n%s", commit.Header(edited), genFormatViolations(violations)))
+	}
+
+	return edited, nil
+}
+
+// genEditCommitMessage edits the parts of a message. The suggested scopes are
+// offered for selection, the first of them preselected when the message has
+// no scope.
+func genEditCommitMessage(message string, commitType commit.Type, scopeSuggestions []scopes.Suggestion) (string, error) {
+	if format, ok := commitType.CustomFormat(); ok {
+		return genEditCustomCommitMessage(message, format)
+	}
+
 	commitMessage := commit.ParseMessage(message)
 
+	isGitmoji := commitType == commit.GitmojiType || commit.IsGitmoji(commitMessage.Type)
+	// keep unicode gitmoji as unicode after editing
+	useGitmojiEmoji := commit.IsGitmojiEmoji(commitMessage.Type)
+
 	err := prompts.Workflow(&commitMessage).
 		ConditionalStep("Type",
 			func() bool {
-				return commitMessage.Type != "" || commitType == commit.ConventionalType
+				return commitMessage.Type != "" || commitType == commit.ConventionalType || commitType == commit.GitmojiType
 			},
 			func() (any, error) {
+				if isGitmoji {
+					return genSelectGitmojiType(commitMessage.Type, useGitmojiEmoji)
+				}
+
 				var options []*prompts.SelectOption[string]
 
 				// in case of unknown type
@@ -361,6 +1057,10 @@ func genEditCommitMessage(message string, commitType commit.Type) (string, error
 				return commitMessage.Type != ""
 			},
 			func() (any, error) {
+				if len(scopeSuggestions) > 0 {
+					return genSelectScope(commitMessage.Scope, scopeSuggestions)
+				}
+
 				initialValue := commitMessage.Scope
 				if commitMessage.Breaking {
 					initialValue += "!"
@@ -400,32 +1100,378 @@ func genEditCommitMessage(message string, commitType commit.Type) (string, error
 	return commitMessage.ToString(), nil
 }
 
+// genEditCustomCommitMessage edits a message in a user-defined commit format,
+// prompting only for the parts the format contains.
+func genEditCustomCommitMessage(message string, format *commit.Format) (string, error) {
+	commitMessage, _ := format.Parse(message)
+
+	err := prompts.Workflow(&commitMessage).
+		ConditionalStep("Type",
+			format.HasType,
+			func() (any, error) {
+				return genSelectOrEnterValue("type", commitMessage.Type, format.Types, true)
+			}).
+		ConditionalStep("Scope",
+			format.HasScope,
+			func() (any, error) {
+				return genSelectOrEnterValue("scope", commitMessage.Scope, format.Scopes, false)
+			}).
+		Step("CommitMessage", func() (any, error) {
+			return prompts.Text(prompts.TextParams{
+				Message:      "Enter a message",
+				Placeholder:  "<message>",
+				InitialValue: commitMessage.CommitMessage,
+				Validate: func(value string) error {
+					if value == "" {
+						return errors.New("please enter a message")
+					}
+					return nil
+				},
+			})
+		}).
+		Run()
+	if err != nil {
+		return "", err
+	}
+
+	return format.Render(commitMessage), nil
+}
+
+// genSelectOrEnterValue prompts to select a value from the allowed values
+// table, or to enter a value if the table is empty.
+func genSelectOrEnterValue(name, current string, allowed map[string]string, required bool) (string, error) {
+	if len(allowed) == 0 {
+		return prompts.Text(prompts.TextParams{
+			Message:      fmt.Sprintf("Enter a %s", name),
+			Placeholder:  fmt.Sprintf("<%s>", name),
+			InitialValue: current,
+			Validate: func(value string) error {
+				if value == "" && required {
+					return fmt.Errorf("please enter a %s", name)
+				}
+				return nil
+			},
+		})
+	}
+
+	var options []*prompts.SelectOption[string]
+
+	// in case of unknown value
+	if _, ok := allowed[current]; !ok && current != "" {
+		options = append(options, &prompts.SelectOption[string]{
+			Label: current,
+			Value: current,
+		})
+	}
+
+	options = append(options, slice.FlatMap(
+		maputil.Keys(allowed),
+		func(_ int, item string) []*prompts.SelectOption[string] {
+			return []*prompts.SelectOption[string]{
+				{Label: item, Value: item, Hint: allowed[item]},
+			}
+		},
+	)...)
+
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].Label < options[j].Label
+	})
+
+	return prompts.Select(prompts.SelectParams[string]{
+		Message:      fmt.Sprintf("Select a %s", name),
+		InitialValue: current,
+		Options:      options,
+	})
+}
+
+// genSelectScope prompts to select one of the suggested scopes, no scope, or
+// to enter another one. Without a current scope, the most relevant suggestion
+// is preselected.
+func genSelectScope(current string, suggestions []scopes.Suggestion) (string, error) {
+	const otherScope = "\x00other"
+
+	initialValue := current
+	if initialValue == "" {
+		initialValue = suggestions[0].Scope
+	}
+
+	var options []*prompts.SelectOption[string]
+
+	// in case of a scope not suggested
+	if !slices.ContainsFunc(suggestions, func(s scopes.Suggestion) bool { return s.Scope == current }) && current != "" {
+		options = append(options, &prompts.SelectOption[string]{
+			Label: current,
+			Value: current,
+		})
+	}
+
+	for _, s := range suggestions {
+		options = append(options, &prompts.SelectOption[string]{
+			Label: s.Scope,
+			Value: s.Scope,
+			Hint:  s.Hint(),
+		})
+	}
+
+	options = append(options,
+		&prompts.SelectOption[string]{Label: "no scope", Value: ""},
+		&prompts.SelectOption[string]{Label: "other", Value: otherScope, Hint: "enter a scope"},
+	)
+
+	scope, err := prompts.Select(prompts.SelectParams[string]{
+		Message:      "Select a scope",
+		InitialValue: initialValue,
+		Options:      options,
+	})
+	if err != nil || scope != otherScope {
+		return scope, err
+	}
+
+	return prompts.Text(prompts.TextParams{
+		Message:     "Enter a scope",
+		Placeholder: "<scope>",
+	})
+}
+
+// genSelectGitmojiType prompts for a gitmoji, returning it either as a
+// shortcode or as unicode emoji.
+func genSelectGitmojiType(current string, asEmoji bool) (string, error) {
+	initialValue, _ := commit.GitmojiShortcode(current)
+
+	var options []*prompts.SelectOption[string]
+
+	// in case of unknown gitmoji
+	if _, ok := commit.GitmojiTypes[initialValue]; !ok {
+		options = append(options, &prompts.SelectOption[string]{
+			Label: current,
+			Value: current,
+		})
+		initialValue = current
+	}
+
+	// add rest of the gitmoji types
+	options = append(options, slice.FlatMap(
+		maputil.Keys(commit.GitmojiTypes),
+		func(_ int, item string) []*prompts.SelectOption[string] {
+			label := item
+			if emoji, ok := commit.GitmojiEmoji(item); ok {
+				label = fmt.Sprintf("%s %s", emoji, item)
+			}
+			return []*prompts.SelectOption[string]{
+				{Label: label, Value: item, Hint: commit.GitmojiTypes[item]},
+			}
+		},
+	)...)
+
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].Value < options[j].Value
+	})
+
+	selected, err := prompts.Select(prompts.SelectParams[string]{
+		Message:      "Select a gitmoji",
+		InitialValue: initialValue,
+		Options:      options,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if asEmoji {
+		if emoji, ok := commit.GitmojiEmoji(selected); ok {
+			return emoji, nil
+		}
+	}
+
+	return selected, nil
+}
+
 func runGenE(cmd *cobra.Command, args []string) error {
+	checkAction, err := checkResolveAction(cmd, genFlags.Check)
+	if err != nil {
+		return err
+	}
+	genFlags.Check = checkAction
+
+	if genFlags.Lang, err = resolveLang(cmd, "gen", genFlags.Lang); err != nil {
+		return err
+	}
+
+	if genFlags.Type, err = resolveCommitType(cmd, genFlags.Type); err != nil {
+		return err
+	}
+
+	if genFlags.Commit, err = resolveCommitOptions(cmd, genFlags.Commit); err != nil {
+		return err
+	}
+
+	if genFlags.Hook != "" {
+		return runGenHook(cmd.Context(), cmd.Flags().Changed("provider"))
+	}
+
+	printMessages := genFlags.Print || genFlags.Output == GenOutputJSON
+
+	if genFlags.NoStage && !printMessages {
+		return errors.New("--no-stage can only be used with --print or --output json")
+	}
+	if genFlags.NoStage && genFlags.All {
+		return errors.New("--no-stage can't be used together with --all")
+	}
+
+	if genFlags.Patch && (genFlags.Yes || printMessages || genFlags.All) {
+		return errors.New("--patch can't be used together with --yes, --print, --output json or --all")
+	}
+
+	if genFlags.Split && (genFlags.Yes || printMessages || len(args) > 0) {
+		return errors.New("--split can't be used together with --yes, --print, --output json or pathspecs")
+	}
+
+	if genFlags.Amend && (genFlags.Split || len(args) > 0) {
+		return errors.New("--amend can't be used together with --split or pathspecs")
+	}
+
+	// printing is non-interactive, and keeps stdout for the output
+	if printMessages {
+		genFlags.Yes = true
+	}
+
 	workDir, err := genSetup(cmd)
 	if err != nil {
 		return err
 	}
 
-	_, diff, err := genDetectAndStageFiles(workDir, genFlags.All)
+	operation, err := gitInProgressOperation(workDir)
 	if err != nil {
 		return err
 	}
 
-	aip, err := initializeLLMProvider(cmd.Flags().Changed("provider"), genFlags.Provider, genFlags.Model)
+	switch operation {
+	case gitOperationRebase:
+		// a rebase stopped by 'edit' or 'break' commits the staged changes as
+		// usual, only its conflicts are resolved with 'git rebase --continue'
+		unmerged, err := gitUnmergedPaths(workDir)
+		if err != nil {
+			return err
+		}
+		if len(unmerged) > 0 {
+			return errors.New("A rebase is in progress. Resolve the conflicts and run 'git rebase --continue'") //nolint:staticcheck
+		}
+	case gitOperationMerge, gitOperationRevert, gitOperationCherryPick:
+		if genFlags.Amend || genFlags.Split || genFlags.Patch || len(args) > 0 {
+			return errors.New("--amend, --split, --patch and pathspecs can't be used during a merge, revert or cherry-pick") //nolint:staticcheck
+		}
+	}
+
+	// merges and reverts are described by what they merge or revert
+	operationMessages := operation == gitOperationMerge || operation == gitOperationRevert
+
+	if genFlags.Patch {
+		if err := genPatchStageHunks(workDir, args); err != nil {
+			return err
+		}
+	}
+
+	var (
+		files              []string
+		excluded           []gitdiff.FileStat
+		diff, amendMessage string
+	)
+	switch {
+	case operationMessages:
+		err = genCheckUnmergedPaths(workDir)
+	case operation == gitOperationCherryPick:
+		// the cherry-picked message is offered first, like when amending
+		if err = genCheckUnmergedPaths(workDir); err == nil {
+			files, diff, excluded, err = genDetectAndStageFiles(workDir, genFlags.All)
+		}
+		if err == nil {
+			amendMessage, err = genCherryPickMessage(workDir)
+		}
+	case genFlags.Amend:
+		diff, amendMessage, excluded, err = genDetectAmendChanges(workDir, genFlags.All, printMessages)
+	case len(args) > 0 && !genFlags.Patch:
+		files, diff, excluded, err = genDetectPathspecFiles(workDir, genFlags.All, args)
+	default:
+		files, diff, excluded, err = genDetectAndStageFiles(workDir, genFlags.All)
+	}
 	if err != nil {
 		return err
 	}
 
-	// set candidate count to 1 when yes flag is true
-	if genFlags.Yes {
+	// the content check runs before the diff is sent to the model, which gets
+	// the diff without the redacted secrets
+	redact, err := checkDiffContent(workDir, diff, genFlags.Check, !genFlags.Yes)
+	if err != nil {
+		return err
+	}
+	promptDiff := check.Redact(diff, redact)
+
+	// a revert of a recent commit is described without the model
+	var revertMessage string
+	if operation == gitOperationNone && !genFlags.Amend && !genFlags.Split {
+		revertMessage, err = genDetectRevert(workDir, files, diff)
+		if err != nil {
+			return err
+		}
+	}
+
+	// dependency updates are described without the model, and otherwise the
+	// dependency changes are given to the model, as lockfiles aren't in the diff
+	var (
+		dependencyMessage string
+		changes           []deps.Change
+	)
+	if operation == gitOperationNone && !genFlags.Amend && !genFlags.Split && revertMessage == "" {
+		changes, dependencyMessage, err = genDetectDependencyChanges(workDir, files, excluded, genFlags.NoStage || (len(args) > 0 && !genFlags.Patch))
+		if err != nil {
+			return err
+		}
+	}
+	scopeSuggestions, err := genScopeSuggestions(workDir, diff)
+	if err != nil {
+		return err
+	}
+	promptContext := genPromptContext(workDir, diff, changes, excluded, scopeSuggestions)
+
+	aip, err := initializeCommitLLMProvider(cmd.Flags().Changed("provider"), genFlags.Provider, genFlags.Model)
+	if err != nil {
+		return err
+	}
+
+	if genFlags.Split {
+		split, err := runGenSplit(cmd.Context(), aip, workDir, diff, redact)
+		if err != nil {
+			return err
+		}
+		if split {
+			prompts.Outro(fmt.Sprintf("%s Successfully committed", picocolors.Green("✔")))
+			return nil
+		}
+	}
+
+	// set candidate count to 1 when yes flag is true, JSON lists all candidates
+	if genFlags.Yes && genFlags.Output != GenOutputJSON {
 		genFlags.CandidateCount = 1
 	}
 
-	messages, err := genMessages(cmd.Context(), aip, genFlags.Type, workDir, diff)
+	var messages []string
+	switch {
+	case operationMessages:
+		messages, err = genOperationMessages(cmd.Context(), aip, workDir, operation)
+	case revertMessage != "":
+		messages = []string{revertMessage}
+	case dependencyMessage != "":
+		messages = []string{dependencyMessage}
+	default:
+		messages, err = genMessages(cmd.Context(), aip, genFlags.Type, workDir, promptDiff, amendMessage, promptContext...)
+	}
 	if err != nil {
 		return err
 	}
 
+	if printMessages {
+		return genPrintMessages(aip, workDir, messages)
+	}
+
 	var message string
 	if genFlags.Yes {
 		// In automatic mode, use the first message
@@ -433,8 +1479,13 @@ func runGenE(cmd *cobra.Command, args []string) error {
 			message = messages[0]
 		}
 	} else {
+		// the existing message is offered first when amending
+		if amendMessage != "" {
+			messages = slice.Unique(append([]string{amendMessage}, messages...))
+		}
+
 		// In interactive mode, let the user select a message
-		message, err = genHandleMessageSelection(messages)
+		message, err = genHandleMessageSelection(workDir, diff, messages, scopeSuggestions)
 		if err != nil {
 			return err
 		}
@@ -444,7 +1495,15 @@ func runGenE(cmd *cobra.Command, args []string) error {
 		return errors.New("no commit message selected") //nolint:staticcheck
 	}
 
-	if err := gitCommit(workDir, message); err != nil {
+	switch {
+	case genFlags.Amend:
+		err = gitCommitAmend(workDir, message, genFlags.Commit)
+	case len(args) > 0 && !genFlags.Patch:
+		err = gitCommitPaths(workDir, message, args, genFlags.Commit)
+	default:
+		err = gitCommit(workDir, message, genFlags.Commit)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -457,6 +1516,209 @@ func runGenE(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// genOutput is the JSON output of the generated messages.
+type genOutput struct {
+	Provider   string         `json:"provider"`
+	Model      string         `json:"model,omitempty"`
+	Type       string         `json:"type"`
+	Candidates []genCandidate `json:"candidates"`
+	Usage      *llm.Usage     `json:"usage,omitempty"`
+}
+
+// genCandidate is a generated message, with its parsed parts.
+type genCandidate struct {
+	Message    string             `json:"message"`
+	Type       string             `json:"type,omitempty"`
+	Scope      string             `json:"scope,omitempty"`
+	Breaking   bool               `json:"breaking,omitempty"`
+	Subject    string             `json:"subject"`
+	Body       string             `json:"body,omitempty"`
+	Violations []commit.Violation `json:"violations,omitempty"`
+}
+
+// genPrintMessages prints the first message, or all messages as JSON.
+func genPrintMessages(aip llm.AIPrompt, workDir string, messages []string) error {
+	if genFlags.Output != GenOutputJSON {
+		fmt.Println(messages[0])
+		return nil
+	}
+
+	rules, err := loadCommitRules(workDir, genFlags.Type)
+	if err != nil {
+		return err
+	}
+
+	output := genOutput{
+		Provider: aip.String(),
+		Type:     commit.TypeIds[genFlags.Type][0],
+	}
+
+	if info, ok := aip.(llm.ProviderInfo); ok {
+		usage := info.Usage()
+		output.Provider = info.Name()
+		output.Model = info.Model()
+		output.Usage = &usage
+	}
+
+	for _, message := range messages {
+		_, body, _ := strings.Cut(message, "\n")
+		m := genFlags.Type.ParseMessage(commit.Header(message))
+
+		output.Candidates = append(output.Candidates, genCandidate{
+			Message:    message,
+			Type:       m.Type,
+			Scope:      m.Scope,
+			Breaking:   m.Breaking,
+			Subject:    m.CommitMessage,
+			Body:       strings.TrimSpace(body),
+			Violations: rules.Lint(genFlags.Type, message),
+		})
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(output)
+}
+
+// runGenHook runs gen as a prepare-commit-msg hook. It never commits or
+// stages anything. The preparation errors, e.g. changes blocked by the content
+// check, fail the commit; when the message can't be generated in time, the
+// message file is left as git prepared it.
+func runGenHook(ctx context.Context, providerChanged bool) error {
+	// git passes a source for merges, squashes, amends (commit) and messages
+	// given with -m, -F or a template, those are kept as they are
+	if genFlags.HookSource != "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(genFlags.Hook)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message file: %w", err)
+	}
+
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return err
+	}
+
+	commentChar := gitCommentChar(workDir)
+
+	// another hook has already prepared a message
+	if commit.StripComments(string(content), commentChar) != "" {
+		return nil
+	}
+
+	genFlags.Yes = true
+
+	// the deadline covers the preparation too, the history passes may take
+	// long in large repositories
+	ctx, cancel := context.WithTimeout(ctx, genFlags.HookTimeout)
+	defer cancel()
+
+	// the provider may not honour the deadline, but the hook must return in time
+	done := make(chan genHookResult, 1)
+	go func() {
+		done <- genHookMessages(ctx, providerChanged, workDir)
+	}()
+
+	var r genHookResult
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = ctx.Err()
+	}
+
+	if r.prepareErr != nil {
+		return r.prepareErr
+	}
+
+	if r.err != nil {
+		fmt.Fprintf(os.Stderr, "%s: commit message not generated: %v\n", AppName, r.err)
+		return nil
+	}
+
+	if len(r.messages) == 0 {
+		return nil
+	}
+
+	return os.WriteFile(genFlags.Hook, []byte(genHookMessageFile(r.messages, string(content), commentChar)), 0o644) //nolint:gosec
+}
+
+// genHookResult is the result of generating the messages in a hook. The
+// preparation errors, e.g. changes blocked by the content check, fail the
+// commit, the generation errors don't.
+type genHookResult struct {
+	messages   []string
+	err        error
+	prepareErr error
+}
+
+// genHookMessages prepares the staged changes and generates their messages,
+// without messages when nothing is staged.
+func genHookMessages(ctx context.Context, providerChanged bool, workDir string) genHookResult {
+	files, diff, excluded, err := gitDiffStaged(workDir)
+	if err != nil || (len(files) == 0 && len(excluded) == 0) {
+		return genHookResult{prepareErr: err}
+	}
+
+	changes, dependencyMessage, err := genDetectDependencyChanges(workDir, files, excluded, false)
+	if err != nil {
+		return genHookResult{prepareErr: err}
+	}
+
+	if dependencyMessage != "" {
+		return genHookResult{messages: []string{dependencyMessage}}
+	}
+
+	redact, err := checkDiffContent(workDir, diff, genFlags.Check, false)
+	if err != nil {
+		return genHookResult{prepareErr: err}
+	}
+
+	scopeSuggestions, err := genScopeSuggestions(workDir, diff)
+	if err != nil {
+		return genHookResult{prepareErr: err}
+	}
+	promptContext := genPromptContext(workDir, diff, changes, excluded, scopeSuggestions)
+
+	aip, err := initializeCommitLLMProvider(providerChanged, genFlags.Provider, genFlags.Model)
+	if err != nil {
+		return genHookResult{err: err}
+	}
+
+	messages, err := genMessages(ctx, aip, genFlags.Type, workDir, check.Redact(diff, redact), "", promptContext...)
+	return genHookResult{messages: messages, err: err}
+}
+
+// genHookMessageFile returns the content of the message file, with the first
+// message as the commit message and the others as commented alternatives
+// above the comments prepared by git.
+func genHookMessageFile(messages []string, content, commentChar string) string {
+	var sb strings.Builder
+
+	sb.WriteString(messages[0])
+	sb.WriteString("\n")
+
+	if len(messages) > 1 {
+		sb.WriteString("\n" + commentChar + " Alternative commit messages generated by " + AppName + ":\n")
+		for _, message := range messages[1:] {
+			sb.WriteString(commentChar + "\n")
+			for _, line := range strings.Split(message, "\n") {
+				sb.WriteString(strings.TrimRight(commentChar+"   "+line, " "))
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	if content = strings.TrimLeft(content, "\n"); content != "" {
+		sb.WriteString("\n")
+		sb.WriteString(content)
+	}
+
+	return sb.String()
+}
+
 func isGenCmd() bool {
 	if workDir, err := gitWorkingTreeDir(getWd()); err != nil || workDir == "" {
 		return false
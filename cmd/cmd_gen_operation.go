@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/orochaa/go-clack/prompts"
+
+	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/llm"
+)
+
+// genMaxMergedSubjects is the maximum number of merged commit subjects sent
+// to the model for a merge commit.
+const genMaxMergedSubjects = 50
+
+// genCheckUnmergedPaths refuses to continue while there are conflicts left.
+func genCheckUnmergedPaths(workDir string) error {
+	unmerged, err := gitUnmergedPaths(workDir)
+	if err != nil {
+		return err
+	}
+
+	if len(unmerged) > 0 {
+		return fmt.Errorf("Resolve the conflicts and stage the files before committing:\n%s", strings.Join(unmerged, "\n")) //nolint:staticcheck
+	}
+
+	return nil
+}
+
+// genOperationMessages generates the messages for committing a merge or a
+// revert in progress.
+func genOperationMessages(ctx context.Context, aip llm.AIPrompt, workDir string, operation gitOperation) ([]string, error) {
+	switch operation {
+	case gitOperationMerge:
+		return genMergeMessages(ctx, aip, workDir)
+	case gitOperationRevert:
+		return genRevertMessages(ctx, aip, workDir)
+	default:
+		return nil, fmt.Errorf("unsupported git operation: %d", operation)
+	}
+}
+
+// genMergeMessages generates merge commit messages, keeping the header
+// prepared by git and describing the merged commits in the body. The files
+// which needed conflict resolution are listed below.
+func genMergeMessages(ctx context.Context, aip llm.AIPrompt, workDir string) ([]string, error) {
+	mergeHeads, err := gitReadStateFile(workDir, "MERGE_HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	heads := strings.Fields(mergeHeads)
+	if len(heads) == 0 {
+		return nil, errors.New("The merge in progress has no merged commits") //nolint:staticcheck
+	}
+
+	// git always prepares the message of a merge, a squash merge writes
+	// SQUASH_MSG instead and leaves no merge in progress; the header names the
+	// merged commit when the prepared message was removed
+	prepared, _ := gitReadStateFile(workDir, "MERGE_MSG")
+	header, conflicts := genParseMergeMessage(prepared)
+	if header == "" {
+		header = fmt.Sprintf("Merge commit '%s'", heads[0][:min(7, len(heads[0]))])
+	}
+
+	var subjects []string
+	for _, head := range heads {
+		commits, err := gitCommitMessages(workDir, "HEAD.."+head)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range commits {
+			subjects = append(subjects, commit.Header(c.Message))
+		}
+	}
+	if len(subjects) > genMaxMergedSubjects {
+		subjects = subjects[:genMaxMergedSubjects]
+	}
+
+	var spinner *prompts.SpinnerController
+	if !genFlags.Yes {
+		spinner = prompts.Spinner(prompts.SpinnerOptions{})
+		spinner.Start("Generating merge commit message")
+		spinner.Message(fmt.Sprintf("Generating merge commit message with %s", aip.String()))
+	}
+
+	bodies, err := llm.GenerateMergeCommitBody(ctx, aip, genFlags.Lang, header, subjects, genFlags.CandidateCount)
+	if err != nil {
+		if spinner != nil {
+			spinner.Stop("Failed to generate merge commit message", 1)
+		}
+		return nil, err
+	}
+
+	if spinner != nil {
+		spinner.Stop("Merge analyzed", 0)
+	}
+
+	var conflictsSection string
+	if len(conflicts) > 0 {
+		conflictsSection = "\n\nConflicts:\n\t" + strings.Join(conflicts, "\n\t")
+	}
+
+	messages := make([]string, len(bodies))
+	for i, body := range bodies {
+		messages[i] = header + "\n\n" + body + conflictsSection
+	}
+
+	return messages, nil
+}
+
+// genParseMergeMessage returns the header of the merge message prepared by
+// git, and the files listed in its commented out conflicts section.
+func genParseMergeMessage(message string) (string, []string) {
+	var (
+		header      string
+		conflicts   []string
+		inConflicts bool
+	)
+
+	for _, line := range strings.Split(message, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# Conflicts:"):
+			inConflicts = true
+		case inConflicts && strings.HasPrefix(line, "#\t"):
+			conflicts = append(conflicts, strings.TrimPrefix(line, "#\t"))
+		case inConflicts && strings.TrimSpace(line) == "#":
+		case strings.HasPrefix(line, "#"):
+			inConflicts = false
+		case header == "" && strings.TrimSpace(line) != "":
+			header = strings.TrimSpace(line)
+		}
+	}
+
+	return header, conflicts
+}
+
+// genRevertMessages generates the revert commit message, referencing the
+// reverted commit. In interactive mode the user can give the reason of the
+// revert, which is summarized in the body.
+func genRevertMessages(ctx context.Context, aip llm.AIPrompt, workDir string) ([]string, error) {
+	revertHead, err := gitReadStateFile(workDir, "REVERT_HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := gitCommitMessages(workDir, revertHead)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("reverted commit %s not found", revertHead)
+	}
+	reverted := commits[0]
+
+	var reason string
+	if !genFlags.Yes {
+		reason, err = prompts.Text(prompts.TextParams{
+			Message:     fmt.Sprintf("Why is %q reverted?", commit.Header(reverted.Message)),
+			Placeholder: "<optional context>",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(reason) != "" {
+		spinner := prompts.Spinner(prompts.SpinnerOptions{})
+		spinner.Start("Generating revert commit message")
+		spinner.Message(fmt.Sprintf("Generating revert commit message with %s", aip.String()))
+
+		reason, err = llm.GenerateRevertReason(ctx, aip, genFlags.Lang, reverted.Message, reason)
+		if err != nil {
+			spinner.Stop("Failed to generate revert commit message", 1)
+			return nil, err
+		}
+
+		spinner.Stop("Revert analyzed", 0)
+	}
+
+	return []string{commit.RevertMessage(genFlags.Type, reverted.Hash, commit.Header(reverted.Message), reason)}, nil
+}
+
+// genCherryPickMessage returns the message of the commit being cherry-picked.
+func genCherryPickMessage(workDir string) (string, error) {
+	cherryPickHead, err := gitReadStateFile(workDir, "CHERRY_PICK_HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	commits, err := gitCommitMessages(workDir, cherryPickHead)
+	if err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", errors.New("cherry-picked commit not found")
+	}
+
+	return commits[0].Message, nil
+}
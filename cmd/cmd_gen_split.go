@@ -0,0 +1,447 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/orochaa/go-clack/prompts"
+	"github.com/orochaa/go-clack/third_party/picocolors"
+
+	"github.com/zbiljic/kai/pkg/check"
+	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/gitdiff"
+	"github.com/zbiljic/kai/pkg/llm"
+	"github.com/zbiljic/kai/pkg/promptsx"
+)
+
+// genSplitAction is the action selected for the proposed commit plan.
+type genSplitAction string
+
+const (
+	genSplitAccept genSplitAction = "accept"
+	genSplitEdit   genSplitAction = "edit"
+	genSplitSingle genSplitAction = "single"
+)
+
+// runGenSplit proposes splitting the staged changes into a sequence of commits,
+// and creates them on top of HEAD. It reports false when the user chose to
+// continue with a single commit instead. The secrets found by the content
+// check are redacted from the hunks sent to the model.
+func runGenSplit(ctx context.Context, aip llm.AIPrompt, workDir, diff string, redact []check.Finding) (bool, error) {
+	hunks, files, err := gitdiff.ParseDiffFiles(diff + "\n")
+	if err != nil {
+		return false, fmt.Errorf("failed to parse diff into hunks: %w", err)
+	}
+
+	if len(hunks)+len(files) < 2 {
+		prompts.Info("The staged changes can't be split, generating a single commit")
+		return false, nil
+	}
+
+	currentBranch, err := gitCurrentBranch(workDir)
+	if err != nil {
+		return false, err
+	}
+
+	staged, err := gitStagedPaths(workDir)
+	if err != nil {
+		return false, err
+	}
+
+	spinner := prompts.Spinner(prompts.SpinnerOptions{})
+	spinner.Start("Analyzing staged changes")
+	spinner.Message(fmt.Sprintf("Using %s to generate commit plan", aip.String()))
+
+	promptHunks := check.RedactHunks(hunks, redact)
+	changes := gitFileChanges(workDir, diff)
+
+	commitPlan, err := llm.GenerateCommitPlan(ctx, aip, promptHunks, files, changes, currentBranch, "HEAD", genFlags.Lang)
+	if err != nil {
+		spinner.Stop("Failed to generate commit plan", 1)
+		return false, err
+	}
+
+	// the plan is generated with conventional commit messages, like in
+	// prprepare, so they are validated against the conventional rules
+	spinner.Message("Validating commit messages")
+	if err := prprepareRepairCommitMessages(ctx, aip, workDir, commit.ConventionalType, genFlags.Lang, commitPlan, promptHunks); err != nil {
+		spinner.Stop("Failed to validate commit messages", 1)
+		return false, err
+	}
+
+	spinner.Stop("Commit plan generated", 0)
+
+	hunksByID := make(map[string]*gitdiff.Hunk, len(hunks))
+	for _, hunk := range hunks {
+		hunksByID[hunk.ID] = hunk
+	}
+
+	for {
+		genSplitShowPlan(commitPlan)
+
+		action, err := prompts.Select(prompts.SelectParams[genSplitAction]{
+			Message: "Create these commits?",
+			Options: []*prompts.SelectOption[genSplitAction]{
+				{Label: "Yes, create the commits", Value: genSplitAccept},
+				{Label: "Edit the commits", Value: genSplitEdit},
+				{Label: "No, create a single commit", Value: genSplitSingle},
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		switch action {
+		case genSplitSingle:
+			return false, nil
+		case genSplitEdit:
+			commitPlan, err = genSplitEditPlan(commitPlan, hunks, hunksByID)
+			if err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		break
+	}
+
+	// the staged files which aren't part of the plan, like the excluded files,
+	// can't be planned, so the user decides where they go
+	otherFiles := genSplitOtherFiles(staged, commitPlan, hunks, changes)
+
+	var otherMessage string
+	if len(otherFiles) > 0 {
+		otherMessage, err = genSplitAskOtherFiles(otherFiles)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := genSplitApplyPlan(workDir, diff, commitPlan, hunks, hunksByID, changes, staged, otherFiles, otherMessage); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func genSplitShowPlan(commitPlan *llm.CommitPlan) {
+	var sb strings.Builder
+	for i, plannedCommit := range commitPlan.Commits {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "%s %s\n", picocolors.Bold(fmt.Sprintf("%d.", i+1)), picocolors.Cyan(plannedCommit.Message))
+		if plannedCommit.Rationale != "" {
+			fmt.Fprintf(&sb, "   %s %s\n", picocolors.Dim("Rationale:"), plannedCommit.Rationale)
+		}
+		fmt.Fprintf(&sb, "   %s %s", picocolors.Dim("Hunks:"), strings.Join(plannedCommit.HunkIDs, ", "))
+		if len(plannedCommit.Files) > 0 {
+			fmt.Fprintf(&sb, "\n   %s %s", picocolors.Dim("Files:"), strings.Join(plannedCommit.Files, ", "))
+		}
+	}
+	promptsx.Note(sb.String())
+}
+
+// genSplitEditPlan lets the user edit the message and the hunks of each
+// planned commit. Each hunk can only be part of one commit, the hunks left
+// unassigned are added to a final commit. The planned files without hunks are
+// kept.
+func genSplitEditPlan(commitPlan *llm.CommitPlan, hunks []*gitdiff.Hunk, hunksByID map[string]*gitdiff.Hunk) (*llm.CommitPlan, error) {
+	assigned := make(map[string]bool)
+	edited := &llm.CommitPlan{}
+
+	for i, plannedCommit := range commitPlan.Commits {
+		message, err := prompts.Text(prompts.TextParams{
+			Message:      fmt.Sprintf("Message of commit %d/%d", i+1, len(commitPlan.Commits)),
+			InitialValue: plannedCommit.Message,
+			Validate: func(value string) error {
+				if strings.TrimSpace(value) == "" {
+					return errors.New("please enter a message")
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		hunkIDs, err := genSplitSelectHunks(fmt.Sprintf("Hunks of commit %d/%d", i+1, len(commitPlan.Commits)), hunks, hunksByID, assigned, plannedCommit.HunkIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(hunkIDs) == 0 && len(plannedCommit.Files) == 0 {
+			continue
+		}
+
+		for _, id := range hunkIDs {
+			assigned[id] = true
+		}
+
+		edited.Commits = append(edited.Commits, llm.PlannedCommit{
+			Message:   message,
+			HunkIDs:   hunkIDs,
+			Files:     plannedCommit.Files,
+			Rationale: plannedCommit.Rationale,
+		})
+	}
+
+	var unassigned []string
+	for _, hunk := range hunks {
+		if !assigned[hunk.ID] {
+			unassigned = append(unassigned, hunk.ID)
+		}
+	}
+
+	if len(unassigned) > 0 {
+		message, err := prompts.Text(prompts.TextParams{
+			Message: fmt.Sprintf("Message of the commit with the %d remaining hunk(s)", len(unassigned)),
+			Validate: func(value string) error {
+				if strings.TrimSpace(value) == "" {
+					return errors.New("please enter a message")
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		edited.Commits = append(edited.Commits, llm.PlannedCommit{
+			Message: message,
+			HunkIDs: unassigned,
+		})
+	}
+
+	return edited, nil
+}
+
+// genSplitSelectHunks lets the user select the hunks of a commit among the
+// hunks not assigned to a previous commit.
+func genSplitSelectHunks(
+	message string,
+	hunks []*gitdiff.Hunk,
+	hunksByID map[string]*gitdiff.Hunk,
+	assigned map[string]bool,
+	selected []string,
+) ([]string, error) {
+	var (
+		files   []string
+		options = make(map[string][]prompts.MultiSelectOption[string])
+	)
+
+	for _, hunk := range hunks {
+		if assigned[hunk.ID] {
+			continue
+		}
+
+		if _, ok := options[hunk.FilePath]; !ok {
+			files = append(files, hunk.FilePath)
+		}
+
+		header, _, _ := strings.Cut(hunk.Content, "\n")
+		additions, deletions := genCountHunkChanges(hunk.Content)
+
+		options[hunk.FilePath] = append(options[hunk.FilePath], prompts.MultiSelectOption[string]{
+			Label:      header,
+			Value:      hunk.ID,
+			Hint:       fmt.Sprintf("+%d −%d", additions, deletions),
+			IsSelected: slice.Contain(selected, hunk.ID),
+		})
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	return promptsx.GroupMultiSelectPreview(promptsx.GroupMultiSelectPreviewParams[string]{
+		Message: message,
+		Groups:  files,
+		Options: options,
+		Preview: func(id string) string {
+			return genFormatHunkPreview(hunksByID[id].Content)
+		},
+	})
+}
+
+// genSplitOtherFiles returns the staged paths which aren't part of any hunk or
+// planned file. Both paths of a renamed file are part of its hunks, or of the
+// planned file.
+func genSplitOtherFiles(staged []string, commitPlan *llm.CommitPlan, hunks []*gitdiff.Hunk, changes []*gitdiff.FileChange) []string {
+	covered := make(map[string]bool)
+	for _, hunk := range hunks {
+		covered[hunk.FilePath] = true
+	}
+	for _, plannedCommit := range commitPlan.Commits {
+		for _, path := range plannedCommit.Files {
+			covered[path] = true
+		}
+	}
+	for _, change := range changes {
+		if covered[change.Path] && change.OldPath != "" {
+			covered[change.OldPath] = true
+		}
+	}
+
+	var files []string
+	for _, path := range staged {
+		if !covered[path] {
+			files = append(files, path)
+		}
+	}
+
+	return files
+}
+
+// genSplitAskOtherFiles lists the staged files which aren't part of the plan
+// and asks whether to add them to a final commit, returning its message, or to
+// leave them staged, returning an empty message.
+func genSplitAskOtherFiles(files []string) (string, error) {
+	prompts.Note(strings.Join(files, "\n"), prompts.NoteOptions{
+		Title: fmt.Sprintf("%d staged file(s) aren't part of the plan", len(files)),
+	})
+
+	final, err := prompts.Confirm(prompts.ConfirmParams{
+		Message:      "Add them to a final commit? Otherwise they remain staged",
+		InitialValue: true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !final {
+		return "", nil
+	}
+
+	return prompts.Text(prompts.TextParams{
+		Message: fmt.Sprintf("Message of the commit with the %d remaining file(s)", len(files)),
+		Validate: func(value string) error {
+			if strings.TrimSpace(value) == "" {
+				return errors.New("please enter a message")
+			}
+			return nil
+		},
+	})
+}
+
+// genSplitApplyPlan creates the planned commits on top of HEAD. All the staged
+// paths are unstaged, and for each commit its hunks and files are staged again
+// before committing. The other files are committed with the other message, or staged
+// again without it. The working tree isn't modified, so on failure the
+// remaining changes are still in the working tree.
+func genSplitApplyPlan(
+	workDir string,
+	diff string,
+	commitPlan *llm.CommitPlan,
+	hunks []*gitdiff.Hunk,
+	hunksByID map[string]*gitdiff.Hunk,
+	changes []*gitdiff.FileChange,
+	staged []string,
+	otherFiles []string,
+	otherMessage string,
+) (err error) {
+	changesByPath := make(map[string]*gitdiff.FileChange, len(changes))
+	for _, change := range changes {
+		changesByPath[change.Path] = change
+	}
+
+	for _, plannedCommit := range commitPlan.Commits {
+		for _, id := range plannedCommit.HunkIDs {
+			if _, ok := hunksByID[id]; !ok {
+				return fmt.Errorf("hunk %s not found in parsed hunks", id)
+			}
+		}
+		for _, path := range plannedCommit.Files {
+			if _, ok := changesByPath[path]; !ok {
+				return fmt.Errorf("file %s not found in the staged changes", path)
+			}
+		}
+	}
+
+	// the staged content of the other files, which may differ from the
+	// working tree
+	tree, err := gitWriteTree(workDir)
+	if err != nil {
+		return err
+	}
+
+	// on failure the commits already created are kept, and the changes which
+	// weren't committed yet are staged again
+	defer func() {
+		if err == nil {
+			return
+		}
+		if restoreErr := gitReadTree(workDir, tree); restoreErr != nil {
+			err = fmt.Errorf("%w\nthe staged changes weren't restored, restore them with 'git read-tree %s': %w", err, tree, restoreErr)
+		}
+	}()
+
+	if err := gitUnstageFiles(workDir, staged); err != nil {
+		return fmt.Errorf("failed to reset staging area: %w", err)
+	}
+
+	assigned := make(map[string]bool)
+
+	for i, plannedCommit := range commitPlan.Commits {
+		commitSpinner := prompts.Spinner(prompts.SpinnerOptions{})
+		commitSpinner.Start(fmt.Sprintf("Creating commit %d/%d", i+1, len(commitPlan.Commits)))
+
+		if err := gitdiff.StageHunks(workDir, plannedCommit.HunkIDs, hunksByID, diff+"\n"); err != nil {
+			commitSpinner.Stop("Failed to stage hunks", 1)
+			return fmt.Errorf("failed to stage hunks for commit %d: %w", i+1, err)
+		}
+
+		if len(plannedCommit.Files) > 0 {
+			if err := gitRestore(workDir, tree, false, getFilePathsForCommit(plannedCommit.Files, changesByPath)); err != nil {
+				commitSpinner.Stop("Failed to stage files", 1)
+				return fmt.Errorf("failed to stage files for commit %d: %w", i+1, err)
+			}
+		}
+
+		if err := gitCommit(workDir, plannedCommit.Message, genFlags.Commit); err != nil {
+			commitSpinner.Stop("Failed to create commit", 1)
+			return fmt.Errorf("failed to create commit %d: %w", i+1, err)
+		}
+
+		for _, id := range plannedCommit.HunkIDs {
+			assigned[id] = true
+		}
+
+		commitSpinner.Stop(fmt.Sprintf("Committed: %s", plannedCommit.Message), 0)
+	}
+
+	if len(otherFiles) > 0 {
+		if err := gitRestore(workDir, tree, false, otherFiles); err != nil {
+			return err
+		}
+
+		if otherMessage != "" {
+			if err := gitCommit(workDir, otherMessage, genFlags.Commit); err != nil {
+				return fmt.Errorf("failed to create the commit of the remaining files: %w", err)
+			}
+			prompts.Success(fmt.Sprintf("Committed: %s", otherMessage))
+		} else {
+			prompts.Warn(fmt.Sprintf("%d file(s) weren't part of any commit and remain staged", len(otherFiles)))
+		}
+	}
+
+	// keep the hunks which weren't part of any commit staged
+	var unassigned []string
+	for _, hunk := range hunks {
+		if !assigned[hunk.ID] {
+			unassigned = append(unassigned, hunk.ID)
+		}
+	}
+
+	if len(unassigned) > 0 {
+		if err := gitdiff.StageHunks(workDir, unassigned, hunksByID, diff+"\n"); err != nil {
+			return fmt.Errorf("failed to stage the remaining hunks: %w", err)
+		}
+		prompts.Warn(fmt.Sprintf("%d hunk(s) weren't part of any commit and remain staged", len(unassigned)))
+	}
+
+	return nil
+}
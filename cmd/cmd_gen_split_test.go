@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zbiljic/kai/pkg/gitdiff"
+	"github.com/zbiljic/kai/pkg/llm"
+)
+
+func TestGenSplitApplyPlanRestoresIndexOnFailure(t *testing.T) {
+	dir, git := testGitRepo(t)
+
+	testWriteFile(t, dir, "a.txt", "a\n", 0o644)
+	testWriteFile(t, dir, "b.txt", "b\n", 0o644)
+	testWriteFile(t, dir, "c.txt", "c\n", 0o644)
+	git("add", "a.txt", "b.txt", "c.txt")
+	git("commit", "-q", "-m", "initial")
+	head := git("rev-parse", "HEAD")
+
+	testWriteFile(t, dir, "a.txt", "a changed\n", 0o644)
+	testWriteFile(t, dir, "b.txt", "b staged\n", 0o644)
+	testWriteFile(t, dir, "c.txt", "c staged\n", 0o644)
+	git("add", "a.txt", "b.txt", "c.txt")
+	// the staged content of c.txt differs from the working tree
+	testWriteFile(t, dir, "c.txt", "c unstaged\n", 0o644)
+
+	// the commit of b.txt is rejected
+	testWriteFile(t, filepath.Join(dir, ".git", "hooks"), "pre-commit",
+		"#!/bin/sh\ngit diff --cached --name-only | grep -q b.txt && exit 1\nexit 0\n", 0o755)
+
+	staged, diff, _, err := gitDiffStaged(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hunks, changes, err := gitdiff.ParseDiffFiles(diff + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hunksByID := make(map[string]*gitdiff.Hunk)
+	hunkIDs := make(map[string][]string)
+	for _, hunk := range hunks {
+		hunksByID[hunk.ID] = hunk
+		hunkIDs[hunk.FilePath] = append(hunkIDs[hunk.FilePath], hunk.ID)
+	}
+
+	plan := &llm.CommitPlan{
+		Commits: []llm.PlannedCommit{
+			{Message: "feat: change a", HunkIDs: hunkIDs["a.txt"]},
+			{Message: "feat: change b", HunkIDs: hunkIDs["b.txt"]},
+			{Message: "feat: change c", HunkIDs: hunkIDs["c.txt"]},
+		},
+	}
+
+	err = genSplitApplyPlan(dir, diff, plan, hunks, hunksByID, changes, staged, nil, "")
+	if err == nil {
+		t.Fatal("genSplitApplyPlan() expected an error")
+	}
+
+	if got := strings.TrimSpace(git("log", "-1", "--format=%s")); got != "feat: change a" {
+		t.Errorf("last commit = %q, want the commit created before the failure", got)
+	}
+	if got := git("rev-parse", "HEAD~1"); got != head {
+		t.Errorf("HEAD~1 = %s, want %s", got, head)
+	}
+
+	// the changes which weren't committed are staged again
+	if got := strings.Fields(git("diff", "--cached", "--name-only")); strings.Join(got, " ") != "b.txt c.txt" {
+		t.Errorf("staged files = %q, want b.txt and c.txt", got)
+	}
+	if got := git("show", ":b.txt"); got != "b staged\n" {
+		t.Errorf("staged b.txt = %q, want the staged content", got)
+	}
+	if got := git("show", ":c.txt"); got != "c staged\n" {
+		t.Errorf("staged c.txt = %q, want the staged content", got)
+	}
+	if got := git("show", ":a.txt"); got != "a changed\n" {
+		t.Errorf("staged a.txt = %q, want the committed content", got)
+	}
+}
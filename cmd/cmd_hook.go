@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// hookName is the git hook managed by the hook command.
+	hookName = "prepare-commit-msg"
+	// hookChainedSuffix is appended to the name of an existing hook, which is
+	// then run by the installed hook before generating the message.
+	hookChainedSuffix = ".kai-chained"
+	// hookMarker identifies the hooks installed by kai.
+	hookMarker = "# Installed by 'kai hook install'"
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the prepare-commit-msg hook",
+	Long: `Manages a prepare-commit-msg hook that generates the commit message when
+committing with 'git commit', e.g. from an editor.
+
+The hook writes the best generated message into the commit message file, with
+the other suggestions commented out below it. Merges, amends and messages given
+with -m, -F or a template are left unchanged. The hook is non-interactive, and
+leaves the message empty if it can't be generated within the timeout.
+
+The hook is installed into the directory git runs hooks from, which respects
+core.hooksPath. An existing hook is kept and run before generating the message.`,
+	Annotations: map[string]string{"group": "other"},
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the prepare-commit-msg hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHookInstallE,
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Uninstall the prepare-commit-msg hook",
+	Args:  cobra.NoArgs,
+	RunE:  runHookUninstallE,
+}
+
+var hookFlags = hookOptions{
+	Timeout: 15 * time.Second,
+}
+
+func hookAddFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&hookFlags.Timeout, "timeout", 15*time.Second, "Maximum time the hook spends generating the message")
+}
+
+func init() {
+	hookAddFlags(hookInstallCmd)
+
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+
+	rootCmd.AddCommand(hookCmd)
+}
+
+type hookOptions struct {
+	Timeout time.Duration
+}
+
+// hookPaths returns the path of the hook and of the chained existing hook.
+func hookPaths() (string, string, error) {
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	hooksDir, err := gitHooksDir(workDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	hookPath := filepath.Join(hooksDir, hookName)
+
+	return hookPath, hookPath + hookChainedSuffix, nil
+}
+
+// hookIsInstalled reports whether the hook at the path was installed by kai.
+func hookIsInstalled(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return strings.Contains(string(content), hookMarker), nil
+}
+
+func hookScript(executable string, timeout time.Duration) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+hook_dir=$(dirname "$0")
+if [ -x "$hook_dir/%s%s" ]; then
+	"$hook_dir/%s%s" "$@" || exit $?
+fi
+exec %s gen --hook "$1" --hook-source "$2" --hook-timeout %s </dev/null
+`, hookMarker, hookName, hookChainedSuffix, hookName, hookChainedSuffix, shellQuote(executable), timeout)
+}
+
+func runHookInstallE(cmd *cobra.Command, args []string) error {
+	hookPath, chainedPath, err := hookPaths()
+	if err != nil {
+		return err
+	}
+
+	installed, err := hookIsInstalled(hookPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(hookPath); err == nil && !installed {
+		if _, err := os.Stat(chainedPath); err == nil {
+			return fmt.Errorf("can't chain the existing hook, %s already exists", chainedPath)
+		}
+		if err := os.Rename(hookPath, chainedPath); err != nil {
+			return fmt.Errorf("failed to chain the existing hook: %w", err)
+		}
+		fmt.Printf("Existing hook moved to %s, it will run before %s\n", chainedPath, AppName)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		executable = AppName
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	if err := os.WriteFile(hookPath, []byte(hookScript(executable, hookFlags.Timeout)), 0o755); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	fmt.Printf("Installed %s hook: %s\n", hookName, hookPath)
+
+	return nil
+}
+
+func runHookUninstallE(cmd *cobra.Command, args []string) error {
+	hookPath, chainedPath, err := hookPaths()
+	if err != nil {
+		return err
+	}
+
+	installed, err := hookIsInstalled(hookPath)
+	if err != nil {
+		return err
+	}
+
+	if !installed {
+		return fmt.Errorf("%s hook at %s wasn't installed by %s", hookName, hookPath, AppName)
+	}
+
+	if err := os.Remove(hookPath); err != nil {
+		return fmt.Errorf("failed to remove hook: %w", err)
+	}
+
+	if _, err := os.Stat(chainedPath); err == nil {
+		if err := os.Rename(chainedPath, hookPath); err != nil {
+			return fmt.Errorf("failed to restore the chained hook: %w", err)
+		}
+		fmt.Printf("Restored the previous hook: %s\n", hookPath)
+	}
+
+	fmt.Printf("Uninstalled %s hook\n", hookName)
+
+	return nil
+}
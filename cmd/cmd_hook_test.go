@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHookScriptExitStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		gen        string
+		chained    string
+		wantCommit bool
+	}{
+		{
+			name:       "generation succeeds",
+			gen:        "exit 0",
+			wantCommit: true,
+		},
+		{
+			name:       "generation fails",
+			gen:        "exit 1",
+			wantCommit: false,
+		},
+		{
+			name:       "chained hook fails",
+			gen:        "exit 0",
+			chained:    "exit 1",
+			wantCommit: false,
+		},
+		{
+			name:       "chained hook succeeds",
+			gen:        "exit 0",
+			chained:    "exit 0",
+			wantCommit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, git := testGitRepo(t)
+
+			// stands in for kai, exiting like the generation would
+			binDir := t.TempDir()
+			testWriteFile(t, binDir, "kai", "#!/bin/sh\n"+tt.gen+"\n", 0o755)
+			executable := filepath.Join(binDir, "kai")
+
+			hooksDir := filepath.Join(dir, ".git", "hooks")
+			testWriteFile(t, hooksDir, hookName, hookScript(executable, time.Second), 0o755)
+			if tt.chained != "" {
+				testWriteFile(t, hooksDir, hookName+hookChainedSuffix, "#!/bin/sh\n"+tt.chained+"\n", 0o755)
+			}
+
+			testWriteFile(t, dir, "file.txt", "content\n", 0o644)
+			git("add", "file.txt")
+
+			cmd := exec.Command("git", "commit", "-q", "-m", "feat: add file")
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+
+			if committed := err == nil; committed != tt.wantCommit {
+				t.Errorf("commit succeeded = %v, want %v: %s", committed, tt.wantCommit, out)
+			}
+		})
+	}
+}
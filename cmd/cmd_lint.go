@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/orochaa/go-clack/third_party/picocolors"
+	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
+
+	"github.com/zbiljic/kai/pkg/commit"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [<range>]",
+	Short: "Validate commit messages against the commit rules",
+	Long: `Validates commit messages against the configured commit format and rules.
+
+The same rules are used to validate the messages generated by 'kai gen', including
+the repository's commitlint configuration if there is one.
+
+Without arguments the HEAD commit is validated. A revision range (e.g. 'main..HEAD')
+validates every commit in the range, and --file validates a commit message file,
+which allows using the command as a 'commit-msg' hook:
+
+  kai lint --file "$1"
+
+Merge, revert, fixup! and squash! commits are skipped. The command exits with a
+non-zero status if any message has violations.`,
+	Annotations: map[string]string{"group": "main"},
+	Args:        cobra.MaximumNArgs(1),
+	RunE:        runLintE,
+}
+
+// LintOutputFormat represents the output formats of the lint command.
+type LintOutputFormat enumflag.Flag
+
+const (
+	// LintOutputHuman prints the violations for humans.
+	LintOutputHuman LintOutputFormat = iota
+	// LintOutputJSON prints the results as JSON.
+	LintOutputJSON
+	// LintOutputGitHub prints the violations as GitHub Actions annotations.
+	LintOutputGitHub
+)
+
+// LintOutputFormatIds maps LintOutputFormat to their string representations.
+var LintOutputFormatIds = map[LintOutputFormat][]string{
+	LintOutputHuman:  {"human"},
+	LintOutputJSON:   {"json"},
+	LintOutputGitHub: {"github"},
+}
+
+var lintFlags = lintOptions{
+	Type:   commit.ConventionalType,
+	File:   "",
+	Output: LintOutputHuman,
+}
+
+func lintAddFlags(cmd *cobra.Command) {
+	addCommitTypeFlag(cmd, &lintFlags.Type, "Type of commit message to validate against")
+	cmd.Flags().StringVarP(&lintFlags.File, "file", "f", "", "Validate the commit message in the file (e.g. in a commit-msg hook)")
+	cmd.Flags().VarP(enumflag.New(&lintFlags.Output, "output", LintOutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format (human, json, github)")
+}
+
+func init() {
+	lintAddFlags(lintCmd)
+
+	rootCmd.AddCommand(lintCmd)
+}
+
+type lintOptions struct {
+	Type   commit.Type
+	File   string
+	Output LintOutputFormat
+}
+
+// lintResult is the validation result of a single commit message.
+type lintResult struct {
+	Hash       string             `json:"hash,omitempty"`
+	File       string             `json:"file,omitempty"`
+	Header     string             `json:"header"`
+	Valid      bool               `json:"valid"`
+	Violations []commit.Violation `json:"violations"`
+}
+
+func runLintE(cmd *cobra.Command, args []string) error {
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return err
+	}
+
+	if lintFlags.File != "" && len(args) > 0 {
+		return errors.New("--file can't be used together with a revision range")
+	}
+
+	if lintFlags.Type, err = resolveCommitType(cmd, lintFlags.Type); err != nil {
+		return err
+	}
+
+	rules, err := loadCommitRules(workDir, lintFlags.Type)
+	if err != nil {
+		return err
+	}
+
+	var results []lintResult
+
+	if lintFlags.File != "" {
+		content, err := os.ReadFile(lintFlags.File)
+		if err != nil {
+			return fmt.Errorf("failed to read commit message file: %w", err)
+		}
+
+		message := commit.StripComments(string(content), "#")
+		if !commit.IsIgnored(message) {
+			results = append(results, lintMessage(rules, message, lintResult{File: lintFlags.File}))
+		}
+	} else {
+		revisionRange := "HEAD"
+		if len(args) > 0 {
+			revisionRange = args[0]
+		}
+
+		commits, err := gitCommitMessages(workDir, revisionRange)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range commits {
+			if len(c.Parents) > 1 || commit.IsIgnored(c.Message) {
+				continue
+			}
+			results = append(results, lintMessage(rules, c.Message, lintResult{Hash: c.Hash}))
+		}
+	}
+
+	if err := lintPrintResults(results, lintFlags.Output); err != nil {
+		return err
+	}
+
+	invalid := 0
+	for _, r := range results {
+		if !r.Valid {
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d commit messages have violations", invalid, len(results))
+	}
+
+	return nil
+}
+
+func lintMessage(rules commit.Rules, message string, result lintResult) lintResult {
+	result.Header = commit.Header(message)
+	result.Violations = rules.Lint(lintFlags.Type, message)
+	result.Valid = len(result.Violations) == 0
+	if result.Violations == nil {
+		result.Violations = []commit.Violation{}
+	}
+	return result
+}
+
+func lintPrintResults(results []lintResult, output LintOutputFormat) error {
+	switch output {
+	case LintOutputJSON:
+		if results == nil {
+			results = []lintResult{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case LintOutputGitHub:
+		for _, r := range results {
+			for _, v := range r.Violations {
+				fmt.Printf("::error title=%s::%s\n", v.Rule, lintEscapeGitHubAnnotation(fmt.Sprintf("%s %s: %s", lintResultName(r), r.Header, v.Message)))
+			}
+		}
+		return nil
+	default:
+		for _, r := range results {
+			if r.Valid {
+				fmt.Printf("%s %s %s\n", picocolors.Green("✔"), picocolors.Dim(lintResultName(r)), r.Header)
+				continue
+			}
+			fmt.Printf("%s %s %s\n", picocolors.Red("✖"), picocolors.Dim(lintResultName(r)), r.Header)
+			for _, v := range r.Violations {
+				fmt.Printf("    %s\n", picocolors.Yellow(v.String()))
+			}
+		}
+		return nil
+	}
+}
+
+// lintResultName returns the short commit hash or the file of the result.
+func lintResultName(r lintResult) string {
+	if r.Hash == "" {
+		return r.File
+	}
+	if len(r.Hash) > 7 {
+		return r.Hash[:7]
+	}
+	return r.Hash
+}
+
+// lintEscapeGitHubAnnotation escapes the annotation message, as required by
+// the GitHub Actions workflow commands.
+func lintEscapeGitHubAnnotation(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/zbiljic/kai/pkg/check"
+	"github.com/zbiljic/kai/pkg/gitdiff"
 	"github.com/zbiljic/kai/pkg/llm"
 )
 
@@ -33,6 +35,7 @@ var prgenFlags = prgenOptions{
 	Model:       "",
 	BaseBranch:  "main",
 	MaxDiffSize: llm.DefaultMaxDiffSize,
+	Check:       CheckActionRedact,
 }
 
 func prgenAddFlags(cmd *cobra.Command) {
@@ -43,6 +46,8 @@ func prgenAddFlags(cmd *cobra.Command) {
 
 func init() {
 	addCommonLLMFlags(prgenCmd, &prgenFlags.Provider, &prgenFlags.Model)
+	addCheckFlag(prgenCmd, &prgenFlags.Check)
+	addLangFlag(prgenCmd, &prgenFlags.Lang)
 	prgenAddFlags(prgenCmd)
 
 	rootCmd.AddCommand(prgenCmd)
@@ -54,6 +59,8 @@ type prgenOptions struct {
 	BaseBranch  string
 	MaxDiffSize int
 	NoContext   bool
+	Check       CheckAction
+	Lang        string
 }
 
 // prgenSetupCommandClackIntro sets up clack intro and injects into command context
@@ -97,6 +104,8 @@ func prgenGeneratePRContent(
 	diff,
 	prContext,
 	prTemplate string,
+	fileChanges []*gitdiff.FileChange,
+	excluded []gitdiff.FileStat,
 ) (string, string, error) {
 	generatePRSpinner := prompts.Spinner(prompts.SpinnerOptions{})
 	generatePRSpinner.Start("Generating PR content")
@@ -111,6 +120,9 @@ func prgenGeneratePRContent(
 		diff,
 		prContext,
 		prTemplate,
+		prgenFlags.Lang,
+		fileChanges,
+		excluded,
 		prgenFlags.MaxDiffSize,
 	)
 	if err != nil {
@@ -128,6 +140,10 @@ func runPrGenE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if prgenFlags.Lang, err = resolveLang(cmd, "prgen", prgenFlags.Lang); err != nil {
+		return err
+	}
+
 	currentBranch, err := gitCurrentBranch(workDir)
 	if err != nil {
 		return fmt.Errorf("failed to get current branch: %w", err)
@@ -188,7 +204,7 @@ func runPrGenE(cmd *cobra.Command, args []string) error {
 
 	fetchingSpinner.Message("Fetching code diff between branches")
 
-	diff, err := gitGetDiffBetweenBranches(workDir, prgenFlags.BaseBranch)
+	diff, excluded, err := gitGetDiffBetweenBranches(workDir, prgenFlags.BaseBranch)
 	if err != nil {
 		fetchingSpinner.Stop("Failed to get diff", 1)
 		return fmt.Errorf("failed to get diff: %w", err)
@@ -196,6 +212,16 @@ func runPrGenE(cmd *cobra.Command, args []string) error {
 
 	fetchingSpinner.Stop(fmt.Sprintf("Found %d commits and %d bytes of diff", strings.Count(commits, "---COMMIT---"), len(diff)), 0)
 
+	checkAction, err := checkResolveAction(cmd, prgenFlags.Check)
+	if err != nil {
+		return err
+	}
+
+	redact, err := checkDiffContent(workDir, diff, checkAction, true)
+	if err != nil {
+		return err
+	}
+
 	providerSpinner := prompts.Spinner(prompts.SpinnerOptions{})
 	providerSpinner.Start("Initializing LLM provider")
 
@@ -214,9 +240,11 @@ func runPrGenE(cmd *cobra.Command, args []string) error {
 		currentBranch,
 		prgenFlags.BaseBranch,
 		commits,
-		diff,
+		check.Redact(diff, redact),
 		prContext,
 		prTemplate,
+		gitFileChanges(workDir, diff),
+		excluded,
 	)
 	if err != nil {
 		return err
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,8 @@ import (
 	"github.com/orochaa/go-clack/third_party/picocolors"
 	"github.com/spf13/cobra"
 
+	"github.com/zbiljic/kai/pkg/check"
+	"github.com/zbiljic/kai/pkg/commit"
 	"github.com/zbiljic/kai/pkg/gitdiff"
 	"github.com/zbiljic/kai/pkg/llm"
 	"github.com/zbiljic/kai/pkg/promptsx"
@@ -36,6 +39,7 @@ var prprepareFlags = prprepareOptions{
 	AutoApply:   false,
 	DryRun:      false,
 	Debug:       false,
+	Check:       CheckActionRedact,
 }
 
 func prprepareAddFlags(cmd *cobra.Command) {
@@ -48,6 +52,9 @@ func prprepareAddFlags(cmd *cobra.Command) {
 
 func init() {
 	addCommonLLMFlags(prprepareCmd, &prprepareFlags.Provider, &prprepareFlags.Model)
+	addCheckFlag(prprepareCmd, &prprepareFlags.Check)
+	addLangFlag(prprepareCmd, &prprepareFlags.Lang)
+	addCommitFlags(prprepareCmd, &prprepareFlags.Commit)
 	prprepareAddFlags(prprepareCmd)
 
 	rootCmd.AddCommand(prprepareCmd)
@@ -61,6 +68,9 @@ type prprepareOptions struct {
 	AutoApply   bool
 	DryRun      bool
 	Debug       bool
+	Check       CheckAction
+	Lang        string
+	Commit      commitOptions
 }
 
 // prprepareSetupCommandClackIntro sets up clack intro and injects into command context
@@ -78,11 +88,13 @@ func prprepareSetup(cmd *cobra.Command) (string, error) {
 	return setupGitWorkDir()
 }
 
-// prprepareApplyCommitPlan executes the AI-generated commit plan
+// prprepareApplyCommitPlan executes the AI-generated commit plan. The files
+// without hunks are restored as they were at HEAD before the reset.
 func prprepareApplyCommitPlan(
 	workDir string,
 	commitPlan *llm.CommitPlan,
 	hunks []*gitdiff.Hunk,
+	files []*gitdiff.FileChange,
 	baseBranch string,
 	dryRun bool,
 ) error {
@@ -92,6 +104,24 @@ func prprepareApplyCommitPlan(
 		hunkMap[hunk.ID] = hunk
 	}
 
+	fileMap := make(map[string]*gitdiff.FileChange, len(files))
+	for _, file := range files {
+		fileMap[file.Path] = file
+	}
+
+	for _, plannedCommit := range commitPlan.Commits {
+		for _, path := range plannedCommit.Files {
+			if _, exists := fileMap[path]; !exists {
+				return fmt.Errorf("file %s not found in the changes without hunks", path)
+			}
+		}
+	}
+
+	originalHead, err := gitRevParse(workDir, "HEAD")
+	if err != nil {
+		return err
+	}
+
 	if dryRun {
 		promptsx.Note(fmt.Sprintf("Would reset to base branch: %s", baseBranch))
 	} else {
@@ -99,7 +129,7 @@ func prprepareApplyCommitPlan(
 		resetSpinner := prompts.Spinner(prompts.SpinnerOptions{})
 		resetSpinner.Start("Resetting to base branch")
 
-		err := gitResetHard(workDir, baseBranch)
+		err = gitResetHard(workDir, baseBranch)
 		if err != nil {
 			resetSpinner.Stop("Failed to reset to base branch", 1)
 			return fmt.Errorf("failed to reset to base branch: %w", err)
@@ -109,7 +139,7 @@ func prprepareApplyCommitPlan(
 	}
 
 	// Get the original diff for patch creation
-	originalDiff, err := gitGetDiffBetweenBranches(workDir, baseBranch)
+	originalDiff, _, err := gitGetDiffBetweenBranches(workDir, baseBranch)
 	if err != nil {
 		return fmt.Errorf("failed to get original diff: %w", err)
 	}
@@ -134,13 +164,17 @@ func prprepareApplyCommitPlan(
 				promptsx.Note(fmt.Sprintf("[debug] Patch written to %s\n       git apply --check --cached %s", patchPath, patchPath))
 			}
 
-			promptsx.InfoWithLastLine(fmt.Sprintf(
+			info := fmt.Sprintf(
 				"Would create commit %d/%d:\n   Message: %s\n   Hunks: %s",
 				i+1,
 				len(commitPlan.Commits),
 				plannedCommit.Message,
 				strings.Join(plannedCommit.HunkIDs, ", "),
-			))
+			)
+			if len(plannedCommit.Files) > 0 {
+				info += "\n   Files: " + strings.Join(plannedCommit.Files, ", ")
+			}
+			promptsx.InfoWithLastLine(info)
 		} else {
 			commitSpinner := prompts.Spinner(prompts.SpinnerOptions{})
 			commitSpinner.Start(fmt.Sprintf("Applying commit %d/%d", i+1, len(commitPlan.Commits)))
@@ -169,8 +203,16 @@ func prprepareApplyCommitPlan(
 				return fmt.Errorf("failed to apply hunks for commit %d: %w", i+1, err)
 			}
 
+			if len(plannedCommit.Files) > 0 {
+				err = gitRestore(workDir, originalHead, true, getFilePathsForCommit(plannedCommit.Files, fileMap))
+				if err != nil {
+					commitSpinner.Stop("Failed to restore files", 1)
+					return fmt.Errorf("failed to restore files for commit %d: %w", i+1, err)
+				}
+			}
+
 			// Create the commit
-			err = gitCommit(workDir, plannedCommit.Message)
+			err = gitCommit(workDir, plannedCommit.Message, prprepareFlags.Commit)
 			if err != nil {
 				commitSpinner.Stop("Failed to create commit", 1)
 				return fmt.Errorf("failed to create commit: %w", err)
@@ -206,6 +248,69 @@ func getHunksForCommit(hunkIDs []string, hunkMap map[string]*gitdiff.Hunk) []*gi
 	return hunks
 }
 
+// getFilePathsForCommit returns the paths of the planned files of a commit,
+// with the original paths of the renamed ones
+func getFilePathsForCommit(files []string, fileMap map[string]*gitdiff.FileChange) []string {
+	var paths []string
+	for _, path := range files {
+		paths = append(paths, path)
+		if file := fileMap[path]; file != nil && file.OldPath != "" {
+			paths = append(paths, file.OldPath)
+		}
+	}
+	return paths
+}
+
+// prprepareRepairCommitMessages validates the planned commit messages against
+// the rules of the commit type, and asks the model to repair the ones that
+// don't pass.
+func prprepareRepairCommitMessages(
+	ctx context.Context,
+	aip llm.AIPrompt,
+	workDir string,
+	commitType commit.Type,
+	lang string,
+	commitPlan *llm.CommitPlan,
+	hunks []*gitdiff.Hunk,
+) error {
+	rules, err := loadCommitRules(workDir, commitType)
+	if err != nil {
+		return err
+	}
+
+	hunksByID := make(map[string]*gitdiff.Hunk, len(hunks))
+	for _, hunk := range hunks {
+		hunksByID[hunk.ID] = hunk
+	}
+
+	for i, plannedCommit := range commitPlan.Commits {
+		message := rules.Fix(commitType, plannedCommit.Message)
+
+		violations := rules.Lint(commitType, message)
+		if len(violations) > 0 {
+			var diff strings.Builder
+			for _, id := range plannedCommit.HunkIDs {
+				if hunk, ok := hunksByID[id]; ok {
+					diff.WriteString(hunk.Content)
+					diff.WriteString("\n")
+				}
+			}
+
+			repaired, err := llm.RepairCommitMessage(ctx, aip, commitType, lang, rules.MaxLength(), diff.String(), message, violations)
+			if err == nil && strings.TrimSpace(repaired) != "" {
+				repaired = rules.Fix(commitType, repaired)
+				if len(rules.Lint(commitType, repaired)) < len(violations) {
+					message = repaired
+				}
+			}
+		}
+
+		commitPlan.Commits[i].Message = message
+	}
+
+	return nil
+}
+
 // prprepareCreateBackupIfNeeded creates a backup branch if needed, checking for existing ones first
 func prprepareCreateBackupIfNeeded(workDir string) (string, error) {
 	backupBranch, isNewBranch, err := createBackupBranchIfNeeded(workDir, true)
@@ -235,6 +340,15 @@ func runPrPrepareE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	lang, err := resolveLang(cmd, "prprepare", prprepareFlags.Lang)
+	if err != nil {
+		return err
+	}
+
+	if prprepareFlags.Commit, err = resolveCommitOptions(cmd, prprepareFlags.Commit); err != nil {
+		return err
+	}
+
 	currentBranch, err := gitCurrentBranch(workDir)
 	if err != nil {
 		return fmt.Errorf("failed to get current branch: %w", err)
@@ -283,7 +397,7 @@ func runPrPrepareE(cmd *cobra.Command, args []string) error {
 	fetchingSpinner := prompts.Spinner(prompts.SpinnerOptions{})
 	fetchingSpinner.Start("Fetching code changes")
 
-	diff, err := gitGetDiffBetweenBranches(workDir, prprepareFlags.BaseBranch)
+	diff, _, err := gitGetDiffBetweenBranches(workDir, prprepareFlags.BaseBranch)
 	if err != nil {
 		fetchingSpinner.Stop("Failed to get diff", 1)
 		return fmt.Errorf("failed to get diff: %w", err)
@@ -300,19 +414,32 @@ func runPrPrepareE(cmd *cobra.Command, args []string) error {
 	parseSpinner := prompts.Spinner(prompts.SpinnerOptions{})
 	parseSpinner.Start("Parsing code changes")
 
-	hunks, err := gitdiff.ParseDiff(diff)
+	hunks, files, err := gitdiff.ParseDiffFiles(diff)
 	if err != nil {
 		parseSpinner.Stop("Failed to parse changes", 1)
 		return fmt.Errorf("failed to parse diff into hunks: %w", err)
 	}
 
-	if len(hunks) == 0 {
+	if len(hunks) == 0 && len(files) == 0 {
 		parseSpinner.Stop("No code hunks found", 1)
 		return fmt.Errorf("no code hunks found in diff")
 	}
 
 	parseSpinner.Stop(fmt.Sprintf("Parsed %d code hunks", len(hunks)), 0)
 
+	checkAction, err := checkResolveAction(cmd, prprepareFlags.Check)
+	if err != nil {
+		return err
+	}
+
+	// the hunks sent to the model have the secrets redacted, the original
+	// hunks are applied
+	redact, err := checkDiffContent(workDir, diff, checkAction, true)
+	if err != nil {
+		return err
+	}
+	promptHunks := check.RedactHunks(hunks, redact)
+
 	// Initialize LLM provider
 	providerSpinner := prompts.Spinner(prompts.SpinnerOptions{})
 	providerSpinner.Start("Initializing LLM provider")
@@ -333,15 +460,24 @@ func runPrPrepareE(cmd *cobra.Command, args []string) error {
 	commitPlan, err := llm.GenerateCommitPlan(
 		cmd.Context(),
 		aip,
-		hunks,
+		promptHunks,
+		files,
+		gitFileChanges(workDir, diff),
 		currentBranch,
 		prprepareFlags.BaseBranch,
+		lang,
 	)
 	if err != nil {
 		spinner.Stop("Failed to generate commit plan", 1)
 		return err
 	}
 
+	spinner.Message("Validating commit messages")
+	if err := prprepareRepairCommitMessages(cmd.Context(), aip, workDir, commit.ConventionalType, lang, commitPlan, promptHunks); err != nil {
+		spinner.Stop("Failed to validate commit messages", 1)
+		return err
+	}
+
 	spinner.Stop("Commit plan generated", 0)
 
 	// Display the commit plan
@@ -353,6 +489,9 @@ func runPrPrepareE(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s %s\n", picocolors.Bold(fmt.Sprintf("%d.", i+1)), picocolors.Cyan(commit.Message))
 		fmt.Printf("   %s %s\n", picocolors.Dim("Rationale:"), commit.Rationale)
 		fmt.Printf("   %s %s\n", picocolors.Dim("Hunks:"), strings.Join(commit.HunkIDs, ", "))
+		if len(commit.Files) > 0 {
+			fmt.Printf("   %s %s\n", picocolors.Dim("Files:"), strings.Join(commit.Files, ", "))
+		}
 		fmt.Println("")
 	}
 
@@ -375,7 +514,7 @@ func runPrPrepareE(cmd *cobra.Command, args []string) error {
 	}
 
 	// Apply the commit plan
-	err = prprepareApplyCommitPlan(workDir, commitPlan, hunks, prprepareFlags.BaseBranch, prprepareFlags.DryRun)
+	err = prprepareApplyCommitPlan(workDir, commitPlan, hunks, files, prprepareFlags.BaseBranch, prprepareFlags.DryRun)
 	if err != nil {
 		if !prprepareFlags.DryRun {
 			prompts.Error("Failed to apply commit plan")
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/orochaa/go-clack/prompts"
+	"github.com/orochaa/go-clack/third_party/picocolors"
+	"github.com/spf13/cobra"
+
+	"github.com/zbiljic/kai/pkg/gitdiff"
+	"github.com/zbiljic/kai/pkg/llm"
+	"github.com/zbiljic/kai/pkg/promptsx"
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve [<path>...]",
+	Short: "Resolve conflicts with AI assistance",
+	Long: `Finds the conflicted files of a merge, rebase, cherry-pick or revert, and asks the model
+for a resolution of each conflict, with an explanation.
+
+Each proposed resolution is shown as a diff to accept, edit or skip. The accepted resolutions
+are written, and files without remaining conflicts are staged. When all conflicts are resolved,
+the operation is only continued after confirmation.`,
+	Annotations: map[string]string{"group": "other"},
+	Args:        cobra.ArbitraryArgs,
+	RunE:        runResolveE,
+}
+
+var resolveFlags = resolveOptions{
+	Provider:     PhindProvider,
+	Model:        "",
+	ContextLines: 20,
+}
+
+func resolveAddFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&resolveFlags.ContextLines, "context", 20, "Number of lines around each conflict to send to LLM")
+}
+
+func init() {
+	addCommonLLMFlags(resolveCmd, &resolveFlags.Provider, &resolveFlags.Model)
+	resolveAddFlags(resolveCmd)
+
+	rootCmd.AddCommand(resolveCmd)
+}
+
+type resolveOptions struct {
+	Provider     ProviderType
+	Model        string
+	ContextLines int
+}
+
+// resolveAction is the action selected for a proposed resolution.
+type resolveAction string
+
+const (
+	resolveAccept resolveAction = "accept"
+	resolveEdit   resolveAction = "edit"
+	resolveSkip   resolveAction = "skip"
+)
+
+// resolveSetupCommandClackIntro sets up clack intro and injects into command context
+func resolveSetupCommandClackIntro(cmd *cobra.Command) {
+	prompts.Intro(picocolors.BgCyan(picocolors.Black(fmt.Sprintf(" %s ", AppName))))
+	// in order to show custom error
+	injectIntoCommandContextWithKey(cmd, ctxKeyClackPromptStarted{}, true)
+}
+
+// resolveFile resolves the conflicts of a file, and reports whether all of
+// them were resolved and the file staged.
+func resolveFile(ctx context.Context, aip llm.AIPrompt, workDir, file string) (bool, error) {
+	path := filepath.Join(workDir, file)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		prompts.Warn(fmt.Sprintf("%s: deleted on one side, resolve it with 'git add' or 'git rm'", file))
+		return false, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	conflicts, err := gitdiff.ParseConflicts(string(content))
+	if err != nil {
+		prompts.Warn(fmt.Sprintf("%s: %s", file, err))
+		return false, nil
+	}
+
+	if len(conflicts) == 0 {
+		prompts.Warn(fmt.Sprintf("%s: no conflict markers found, resolve it manually", file))
+		return false, nil
+	}
+
+	prompts.Info(fmt.Sprintf("%s: %d conflict(s)", picocolors.Cyan(file), len(conflicts)))
+
+	lines := strings.Split(string(content), "\n")
+	resolutions := make(map[int]string)
+
+	for i, conflict := range conflicts {
+		before := lines[max(0, conflict.StartLine-resolveFlags.ContextLines):conflict.StartLine]
+		after := lines[conflict.EndLine+1 : min(len(lines), conflict.EndLine+1+resolveFlags.ContextLines)]
+
+		spinner := prompts.Spinner(prompts.SpinnerOptions{})
+		spinner.Start(fmt.Sprintf("Resolving conflict %d/%d", i+1, len(conflicts)))
+		spinner.Message(fmt.Sprintf("Resolving conflict %d/%d with %s", i+1, len(conflicts), aip.String()))
+
+		resolution, err := llm.ResolveConflict(ctx, aip, file, conflict.String(), strings.Join(before, "\n"), strings.Join(after, "\n"))
+		if err != nil {
+			spinner.Stop(fmt.Sprintf("Failed to resolve conflict %d/%d: %s", i+1, len(conflicts), err), 1)
+			continue
+		}
+
+		spinner.Stop(fmt.Sprintf("Conflict %d/%d (line %d)", i+1, len(conflicts), conflict.StartLine+1), 0)
+
+		resolved, ok, err := resolveReviewConflict(file, conflict, resolution)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			resolutions[i] = resolved
+		}
+	}
+
+	if len(resolutions) == 0 {
+		return false, nil
+	}
+
+	resolvedContent := gitdiff.ResolveConflicts(string(content), conflicts, resolutions)
+	if err := os.WriteFile(path, []byte(resolvedContent), info.Mode()); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	if len(resolutions) < len(conflicts) {
+		prompts.Warn(fmt.Sprintf("%s: %d conflict(s) left", file, len(conflicts)-len(resolutions)))
+		return false, nil
+	}
+
+	if err := gitStageFiles(workDir, []string{file}); err != nil {
+		return false, fmt.Errorf("failed to stage %s: %w", file, err)
+	}
+
+	return true, nil
+}
+
+// resolveReviewConflict shows the proposed resolution of a conflict for
+// accept, edit or skip, and returns the accepted resolution.
+func resolveReviewConflict(file string, conflict gitdiff.Conflict, resolution *llm.ConflictResolution) (string, bool, error) {
+	resolved := resolution.Resolution
+
+	for {
+		var sb strings.Builder
+		if resolution.Explanation != "" {
+			fmt.Fprintf(&sb, "%s\n\n", resolution.Explanation)
+		}
+		sb.WriteString(genFormatHunkPreview(resolveFormatDiff(conflict, resolved)))
+		promptsx.Note(sb.String())
+
+		action, err := prompts.Select(prompts.SelectParams[resolveAction]{
+			Message: "Use this resolution?",
+			Options: []*prompts.SelectOption[resolveAction]{
+				{Label: "Yes, accept the resolution", Value: resolveAccept},
+				{Label: "Edit the resolution", Value: resolveEdit},
+				{Label: "No, skip the conflict", Value: resolveSkip},
+			},
+		})
+		if err != nil {
+			return "", false, err
+		}
+
+		switch action {
+		case resolveAccept:
+			return resolved, true, nil
+		case resolveSkip:
+			return "", false, nil
+		}
+
+		edited, err := promptsx.EditInEditor(resolved+"\n", "kai-resolve-*"+filepath.Ext(file))
+		if err != nil {
+			return "", false, err
+		}
+
+		if strings.Contains(edited, "<<<<<<<") || strings.Contains(edited, ">>>>>>>") {
+			prompts.Warn("The edited resolution still contains conflict markers")
+			continue
+		}
+
+		resolved = strings.TrimSuffix(edited, "\n")
+	}
+}
+
+// resolveFormatDiff returns the replacement of the conflict region by the
+// resolution as a hunk.
+func resolveFormatDiff(conflict gitdiff.Conflict, resolved string) string {
+	lines := []string{fmt.Sprintf("@@ conflict at line %d @@", conflict.StartLine+1)}
+	for _, line := range strings.Split(conflict.String(), "\n") {
+		lines = append(lines, "-"+line)
+	}
+	if resolved != "" {
+		for _, line := range strings.Split(resolved, "\n") {
+			lines = append(lines, "+"+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolveContinueOperation offers to continue the operation in progress once
+// all conflicts are resolved.
+func resolveContinueOperation(workDir string) error {
+	operation, err := gitInProgressOperation(workDir)
+	if err != nil {
+		return err
+	}
+
+	if operation == gitOperationNone {
+		prompts.Outro(fmt.Sprintf("%s All conflicts resolved", picocolors.Green("✔")))
+		return nil
+	}
+
+	confirmed, err := prompts.Confirm(prompts.ConfirmParams{
+		Message: fmt.Sprintf("All conflicts are resolved. Continue the %s?", operation),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		prompts.Outro(fmt.Sprintf("Run 'git %s --continue' when ready", operation))
+		return nil
+	}
+
+	if err := gitContinueOperation(workDir, operation); err != nil {
+		promptsx.ErrorNoSplitLines(err.Error())
+		prompts.Outro("Resolve the new conflicts with 'kai resolve'")
+		return nil
+	}
+
+	prompts.Outro(fmt.Sprintf("%s Successfully continued the %s", picocolors.Green("✔"), operation))
+
+	return nil
+}
+
+func runResolveE(cmd *cobra.Command, args []string) error {
+	resolveSetupCommandClackIntro(cmd)
+
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return err
+	}
+
+	files, err := gitConflictedFiles(workDir)
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		var selected []string
+		for _, file := range files {
+			for _, arg := range args {
+				if rel, err := filepath.Rel(workDir, filepath.Join(getWd(), arg)); err == nil && (file == rel || strings.HasPrefix(file, rel+"/")) {
+					selected = append(selected, file)
+					break
+				}
+			}
+		}
+		files = selected
+	}
+
+	if len(files) > 0 {
+		aip, err := initializeLLMProvider(cmd.Flags().Changed("provider"), resolveFlags.Provider, resolveFlags.Model)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			staged, err := resolveFile(cmd.Context(), aip, workDir, file)
+			if err != nil {
+				return err
+			}
+			if staged {
+				prompts.Info(fmt.Sprintf("%s: resolved and staged", picocolors.Green(file)))
+			}
+		}
+	}
+
+	remaining, err := gitConflictedFiles(workDir)
+	if err != nil {
+		return err
+	}
+
+	if len(remaining) > 0 {
+		prompts.Outro(fmt.Sprintf("%d file(s) still have conflicts: %s", len(remaining), strings.Join(remaining, ", ")))
+		return nil
+	}
+
+	return resolveContinueOperation(workDir)
+}
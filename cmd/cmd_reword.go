@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/orochaa/go-clack/prompts"
+	"github.com/orochaa/go-clack/third_party/picocolors"
+	"github.com/spf13/cobra"
+	"github.com/zbiljic/gitexec"
+
+	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/llm"
+	"github.com/zbiljic/kai/pkg/promptsx"
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <range>",
+	Short: "Regenerate the messages of existing commits",
+	Long: `Generates a new message for each commit in the revision range (e.g. 'main..HEAD')
+from the commit's own diff, using the messages of the other commits in the range as context.
+
+The old and new messages are shown for approval, and the messages are rewritten in a
+single rebase which keeps the trees, authors and dates of the commits. A backup branch
+is created before rewriting. The range must end at HEAD and can't contain merge commits.`,
+	Annotations: map[string]string{"group": "main"},
+	Args:        cobra.ExactArgs(1),
+	RunE:        runRewordE,
+}
+
+var rewordFlags = rewordOptions{
+	Type:     commit.ConventionalType,
+	Provider: PhindProvider,
+	Model:    "",
+	Yes:      false,
+	Lint:     true,
+	DryRun:   false,
+}
+
+func rewordAddFlags(cmd *cobra.Command) {
+	addCommitTypeFlag(cmd, &rewordFlags.Type, "Type of commit message to generate")
+	cmd.Flags().BoolVarP(&rewordFlags.Yes, "yes", "y", false, "Rewrite the messages without confirmation")
+	cmd.Flags().BoolVar(&rewordFlags.Lint, "lint", true, "Validate generated commit messages and ask the model to repair violations")
+	cmd.Flags().BoolVar(&rewordFlags.DryRun, "dry-run", false, "Only show the new messages, without rewriting the commits")
+}
+
+func init() {
+	addCommonLLMFlags(rewordCmd, &rewordFlags.Provider, &rewordFlags.Model)
+	addLangFlag(rewordCmd, &rewordFlags.Lang)
+	addCommitSignFlags(rewordCmd, &rewordFlags.Commit)
+	addCommitVerifyFlag(rewordCmd, &rewordFlags.Commit)
+	rewordAddFlags(rewordCmd)
+
+	rootCmd.AddCommand(rewordCmd)
+}
+
+type rewordOptions struct {
+	Type     commit.Type
+	Provider ProviderType
+	Model    string
+	Yes      bool
+	Lint     bool
+	DryRun   bool
+	Lang     string
+	Commit   commitOptions
+}
+
+// rewordSetupCommandClackIntro sets up clack intro and injects into command context
+func rewordSetupCommandClackIntro(cmd *cobra.Command) {
+	prompts.Intro(picocolors.BgCyan(picocolors.Black(fmt.Sprintf(" %s ", AppName))))
+	// in order to show custom error
+	injectIntoCommandContextWithKey(cmd, ctxKeyClackPromptStarted{}, true)
+}
+
+// rewordDetectCommits returns the commits in the revision range, oldest first.
+// The range must be a linear history ending at HEAD.
+func rewordDetectCommits(workDir, revisionRange string) ([]gitCommitMessage, error) {
+	commits, err := gitCommitMessages(workDir, revisionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("No commits found in '%s'", revisionRange) //nolint:staticcheck
+	}
+
+	headOut, err := gitexec.RevParse(&gitexec.RevParseOptions{
+		CmdDir: workDir,
+		Arg:    []string{"HEAD"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current commit: %w", err)
+	}
+
+	if commits[0].Hash != strings.TrimSpace(string(headOut)) {
+		return nil, fmt.Errorf("The range '%s' must end at HEAD", revisionRange) //nolint:staticcheck
+	}
+
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			return nil, fmt.Errorf("The range '%s' contains the merge commit %s, which can't be reworded", revisionRange, c.Hash[:7]) //nolint:staticcheck
+		}
+	}
+
+	slice.Reverse(commits)
+
+	return commits, nil
+}
+
+// rewordGenerateMessages generates a new message for each commit from its own
+// diff. Commits whose message doesn't change aren't part of the result.
+func rewordGenerateMessages(ctx context.Context, aip llm.AIPrompt, workDir string, commits []gitCommitMessage) (map[string]string, error) {
+	rules, err := loadCommitRules(workDir, rewordFlags.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := slice.Map(commits, func(_ int, c gitCommitMessage) string {
+		return commit.Header(c.Message)
+	})
+
+	spinner := prompts.Spinner(prompts.SpinnerOptions{})
+	spinner.Start("Generating commit messages")
+
+	messages := make(map[string]string)
+
+	for i, c := range commits {
+		spinner.Message(fmt.Sprintf("Generating message %d/%d with %s", i+1, len(commits), aip.String()))
+
+		parent := gitEmptyTree
+		if len(c.Parents) > 0 {
+			parent = c.Parents[0]
+		}
+
+		_, diff, _, err := gitDiffChanges(workDir, false, parent+".."+c.Hash, nil)
+		if err != nil {
+			spinner.Stop("Failed to get commit diff", 1)
+			return nil, fmt.Errorf("failed to get diff of commit %s: %w", c.Hash[:7], err)
+		}
+
+		// nothing to describe, e.g. only lock files changed
+		if diff == "" {
+			continue
+		}
+
+		siblings := append(append([]string{}, headers[:i]...), headers[i+1:]...)
+
+		generated, err := llm.GenerateRewordCommitMessage(ctx, aip, rewordFlags.Type, rewordFlags.Lang, diff, c.Message, siblings, 1)
+		if err != nil {
+			spinner.Stop("Failed to generate commit messages", 1)
+			return nil, err
+		}
+
+		generated, err = filterAndProcessMessages(generated, rewordFlags.Type, rules)
+		if err != nil {
+			spinner.Stop("Failed to generate commit messages", 1)
+			return nil, err
+		}
+
+		if rewordFlags.Lint {
+			generated, _ = genRepairMessages(ctx, aip, rewordFlags.Type, rewordFlags.Lang, rules, diff, generated)
+		}
+
+		if generated[0] != c.Message {
+			messages[c.Hash] = generated[0]
+		}
+	}
+
+	spinner.Stop("Commit messages generated", 0)
+
+	return messages, nil
+}
+
+// rewordShowMessages shows the old and new message of each commit.
+func rewordShowMessages(commits []gitCommitMessage, messages map[string]string) {
+	var sb strings.Builder
+	for i, c := range commits {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		hash := picocolors.Bold(c.Hash[:7])
+		message, ok := messages[c.Hash]
+		if !ok {
+			fmt.Fprintf(&sb, "%s %s %s", hash, commit.Header(c.Message), picocolors.Dim("(unchanged)"))
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%s %s\n", hash, picocolors.Dim(commit.Header(c.Message)))
+		fmt.Fprintf(&sb, "%s %s", strings.Repeat(" ", 5)+picocolors.Green("→"), picocolors.Cyan(commit.Header(message)))
+	}
+	promptsx.Note(sb.String())
+}
+
+func runRewordE(cmd *cobra.Command, args []string) error {
+	rewordSetupCommandClackIntro(cmd)
+
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return err
+	}
+
+	if rewordFlags.Lang, err = resolveLang(cmd, "reword", rewordFlags.Lang); err != nil {
+		return err
+	}
+
+	if rewordFlags.Type, err = resolveCommitType(cmd, rewordFlags.Type); err != nil {
+		return err
+	}
+
+	if rewordFlags.Commit, err = resolveCommitOptions(cmd, rewordFlags.Commit); err != nil {
+		return err
+	}
+
+	commits, err := rewordDetectCommits(workDir, args[0])
+	if err != nil {
+		return err
+	}
+
+	aip, err := initializeLLMProvider(cmd.Flags().Changed("provider"), rewordFlags.Provider, rewordFlags.Model)
+	if err != nil {
+		return err
+	}
+
+	messages, err := rewordGenerateMessages(cmd.Context(), aip, workDir, commits)
+	if err != nil {
+		return err
+	}
+
+	rewordShowMessages(commits, messages)
+
+	if len(messages) == 0 {
+		prompts.Outro("All commit messages are unchanged")
+		return nil
+	}
+
+	if rewordFlags.DryRun {
+		prompts.Outro("Dry run completed, no changes made")
+		return nil
+	}
+
+	if !rewordFlags.Yes {
+		confirmed, err := prompts.Confirm(prompts.ConfirmParams{
+			Message: fmt.Sprintf("Reword %d commit(s)?", len(messages)),
+		})
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return errors.New("Reword cancelled") //nolint:staticcheck
+		}
+	}
+
+	backupBranch, isNewBranch, err := createBackupBranchIfNeeded(workDir, true)
+	if err != nil {
+		return err
+	}
+	if isNewBranch {
+		prompts.Info(fmt.Sprintf("Created backup branch: %s", backupBranch))
+	} else {
+		prompts.Info(fmt.Sprintf("Using existing backup branch: %s", backupBranch))
+	}
+
+	spinner := prompts.Spinner(prompts.SpinnerOptions{})
+	spinner.Start("Rewriting commit messages")
+
+	if err := gitRewordCommits(workDir, commits, messages, rewordFlags.Commit); err != nil {
+		spinner.Stop("Failed to rewrite commit messages", 1)
+		return err
+	}
+
+	spinner.Stop(fmt.Sprintf("Reworded %d commit(s)", len(messages)), 0)
+
+	prompts.Outro(fmt.Sprintf("%s Successfully reworded", picocolors.Green("✔")))
+
+	return nil
+}
@@ -25,9 +25,11 @@ var rootCmd = &cobra.Command{
 		Commit:  buildinfo.GitCommit,
 		BuiltBy: buildinfo.BuiltBy,
 	}.String(),
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt)
 		cmd.SetContext(ctx)
+
+		return checkTerminal()
 	},
 	RunE:          runRootE,
 	SilenceErrors: true,
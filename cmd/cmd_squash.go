@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/duke-git/lancet/v2/strutil"
+	"github.com/orochaa/go-clack/prompts"
+	"github.com/orochaa/go-clack/third_party/picocolors"
+	"github.com/spf13/cobra"
+
+	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/llm"
+	"github.com/zbiljic/kai/pkg/promptsx"
+)
+
+var squashMsgCmd = &cobra.Command{
+	Use:   "squash-msg",
+	Short: "Generate the squash-merge commit message of the current branch",
+	Long: `Generates a single commit message summarizing all the commits of the current branch,
+for repositories which squash-merge branches. The message is printed to stdout.`,
+	Annotations: map[string]string{"group": "other"},
+	Args:        cobra.NoArgs,
+	RunE:        runSquashMsgE,
+}
+
+var squashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Squash the commits of the current branch into a single commit",
+	Long: `Squashes all the commits of the current branch into a single commit on top of the
+merge base with the base branch, with a message summarizing the branch.
+
+A backup branch is created before the branch is reset.`,
+	Annotations: map[string]string{"group": "other"},
+	Args:        cobra.NoArgs,
+	RunE:        runSquashE,
+}
+
+var squashFlags = squashOptions{
+	Type:        commit.ConventionalType,
+	Provider:    PhindProvider,
+	Model:       "",
+	BaseBranch:  "main",
+	MaxDiffSize: llm.DefaultMaxDiffSize,
+	Lint:        true,
+	Yes:         false,
+}
+
+func squashAddFlags(cmd *cobra.Command) {
+	addCommitTypeFlag(cmd, &squashFlags.Type, "Type of commit message to generate")
+	cmd.Flags().StringVarP(&squashFlags.BaseBranch, "base", "b", "main", "Base branch the current branch is squash-merged into")
+	cmd.Flags().IntVar(&squashFlags.MaxDiffSize, "max-diff", llm.DefaultMaxDiffSize, "Maximum size of diff to send to LLM (in characters)")
+	cmd.Flags().BoolVar(&squashFlags.Lint, "lint", true, "Validate the generated commit message and ask the model to repair violations")
+}
+
+func init() {
+	addCommonLLMFlags(squashMsgCmd, &squashFlags.Provider, &squashFlags.Model)
+	squashAddFlags(squashMsgCmd)
+	addLangFlag(squashMsgCmd, &squashFlags.Lang)
+
+	addCommonLLMFlags(squashCmd, &squashFlags.Provider, &squashFlags.Model)
+	squashAddFlags(squashCmd)
+	addLangFlag(squashCmd, &squashFlags.Lang)
+	squashCmd.Flags().BoolVarP(&squashFlags.Yes, "yes", "y", false, "Squash the commits without confirmation")
+	addCommitFlags(squashCmd, &squashFlags.Commit)
+
+	rootCmd.AddCommand(squashMsgCmd)
+	rootCmd.AddCommand(squashCmd)
+}
+
+type squashOptions struct {
+	Type        commit.Type
+	Provider    ProviderType
+	Model       string
+	BaseBranch  string
+	MaxDiffSize int
+	Lint        bool
+	Yes         bool
+	Lang        string
+	Commit      commitOptions
+}
+
+// squashSetupCommandClackIntro sets up clack intro and injects into command context
+func squashSetupCommandClackIntro(cmd *cobra.Command) {
+	prompts.Intro(picocolors.BgCyan(picocolors.Black(fmt.Sprintf(" %s ", AppName))))
+	// in order to show custom error
+	injectIntoCommandContextWithKey(cmd, ctxKeyClackPromptStarted{}, true)
+}
+
+// squashDetectBranch returns the base branch, checking the remote branches
+// when it doesn't exist locally, and the merge base with the current branch.
+func squashDetectBranch(workDir string) (string, string, error) {
+	currentBranch, err := gitCurrentBranch(workDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	baseBranch := squashFlags.BaseBranch
+	if currentBranch == baseBranch {
+		return "", "", fmt.Errorf("you are currently on the %s branch - please switch to your feature branch", baseBranch)
+	}
+
+	if !gitBranchExists(workDir, baseBranch) {
+		if !gitRemoteBranchExists(workDir, baseBranch) {
+			return "", "", fmt.Errorf("base branch '%s' does not exist locally or remotely", baseBranch)
+		}
+		baseBranch = "origin/" + baseBranch
+	}
+
+	mergeBase, err := gitMergeBase(workDir, baseBranch, "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+
+	return baseBranch, mergeBase, nil
+}
+
+// squashGenerateMessage generates the message summarizing the commits of the
+// branch since the merge base.
+func squashGenerateMessage(ctx context.Context, aip llm.AIPrompt, workDir, mergeBase string) (string, error) {
+	commits, err := gitGetCommitsBetweenBranches(workDir, mergeBase)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	if commits == "" {
+		return "", errors.New("No commits to squash on the current branch") //nolint:staticcheck
+	}
+
+	_, diff, _, err := gitDiffChanges(workDir, false, mergeBase+"..HEAD", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	message, err := llm.GenerateSquashCommitMessage(ctx, aip, squashFlags.Type, squashFlags.Lang, commits, diff, squashFlags.MaxDiffSize)
+	if err != nil {
+		return "", err
+	}
+
+	rules, err := loadCommitRules(workDir, squashFlags.Type)
+	if err != nil {
+		return "", err
+	}
+
+	message = rules.Fix(squashFlags.Type, message)
+	if squashFlags.Lint {
+		messages, _ := genRepairMessages(ctx, aip, squashFlags.Type, squashFlags.Lang, rules, diff, []string{message})
+		message = messages[0]
+	}
+
+	if strutil.IsBlank(message) {
+		return "", errors.New("No commit message was generated. Try again.") //nolint:staticcheck
+	}
+
+	return message, nil
+}
+
+func runSquashMsgE(cmd *cobra.Command, args []string) error {
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return err
+	}
+
+	if squashFlags.Lang, err = resolveLang(cmd, "squash", squashFlags.Lang); err != nil {
+		return err
+	}
+
+	if squashFlags.Type, err = resolveCommitType(cmd, squashFlags.Type); err != nil {
+		return err
+	}
+
+	_, mergeBase, err := squashDetectBranch(workDir)
+	if err != nil {
+		return err
+	}
+
+	aip, err := initializeLLMProvider(cmd.Flags().Changed("provider"), squashFlags.Provider, squashFlags.Model)
+	if err != nil {
+		return err
+	}
+
+	message, err := squashGenerateMessage(cmd.Context(), aip, workDir, mergeBase)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(message)
+
+	return nil
+}
+
+func runSquashE(cmd *cobra.Command, args []string) error {
+	squashSetupCommandClackIntro(cmd)
+
+	workDir, err := setupGitWorkDir()
+	if err != nil {
+		return err
+	}
+
+	if squashFlags.Lang, err = resolveLang(cmd, "squash", squashFlags.Lang); err != nil {
+		return err
+	}
+
+	if squashFlags.Type, err = resolveCommitType(cmd, squashFlags.Type); err != nil {
+		return err
+	}
+
+	if squashFlags.Commit, err = resolveCommitOptions(cmd, squashFlags.Commit); err != nil {
+		return err
+	}
+
+	baseBranch, mergeBase, err := squashDetectBranch(workDir)
+	if err != nil {
+		return err
+	}
+
+	// the staged changes would end up in the squashed commit
+	stagedFiles, _, stagedExcluded, err := gitDiffStaged(workDir)
+	if err != nil {
+		return err
+	}
+	if len(stagedFiles) > 0 || len(stagedExcluded) > 0 {
+		return errors.New("Commit or unstage the staged changes before squashing") //nolint:staticcheck
+	}
+
+	prompts.Info(fmt.Sprintf("Squashing the commits since %s (%s)", picocolors.Cyan(baseBranch), mergeBase[:7]))
+
+	aip, err := initializeLLMProvider(cmd.Flags().Changed("provider"), squashFlags.Provider, squashFlags.Model)
+	if err != nil {
+		return err
+	}
+
+	spinner := prompts.Spinner(prompts.SpinnerOptions{})
+	spinner.Start("Generating squash commit message")
+	spinner.Message(fmt.Sprintf("Analyzing branch with %s", aip.String()))
+
+	message, err := squashGenerateMessage(cmd.Context(), aip, workDir, mergeBase)
+	if err != nil {
+		spinner.Stop("Failed to generate commit message", 1)
+		return err
+	}
+
+	spinner.Stop("Commit message generated", 0)
+
+	promptsx.Note(message)
+
+	if !squashFlags.Yes {
+		confirmed, err := prompts.Confirm(prompts.ConfirmParams{
+			Message: "Squash the commits with this message?",
+		})
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return errors.New("Squash cancelled") //nolint:staticcheck
+		}
+	}
+
+	backupBranch, isNewBranch, err := createBackupBranchIfNeeded(workDir, true)
+	if err != nil {
+		return err
+	}
+	if isNewBranch {
+		prompts.Info(fmt.Sprintf("Created backup branch: %s", backupBranch))
+	} else {
+		prompts.Info(fmt.Sprintf("Using existing backup branch: %s", backupBranch))
+	}
+
+	if err := gitResetSoft(workDir, mergeBase); err != nil {
+		return err
+	}
+
+	if err := gitCommit(workDir, message, squashFlags.Commit); err != nil {
+		return fmt.Errorf("failed to commit, the changes are staged and the commits are on %s: %w", backupBranch, err)
+	}
+
+	prompts.Outro(fmt.Sprintf("%s Successfully squashed into: %s", picocolors.Green("✔"), commit.Header(message)))
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// testGitRepo creates a git repository in a temporary directory, and returns
+// it with a function running git in it which fails the test on errors.
+func testGitRepo(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return string(out)
+	}
+
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test")
+
+	return dir, git
+}
+
+// testWriteFile writes the content to the file in the directory.
+func testWriteFile(t *testing.T, dir, name, content string, perm os.FileMode) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zbiljic/kai/internal/config"
+	"github.com/zbiljic/kai/pkg/commit"
+)
+
+// registerCommitFormats registers the user-defined commit formats from the
+// configuration, making them selectable with the --type flag. The invalid
+// formats are skipped with a warning, so they don't break the other formats.
+func registerCommitFormats() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	// register in a stable order so that type values don't change between runs
+	names := make([]string, 0, len(cfg.CommitFormats))
+	for name := range cfg.CommitFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		formatConfig := cfg.CommitFormats[name]
+
+		format, err := commit.NewFormat(
+			name,
+			formatConfig.Description,
+			formatConfig.Format,
+			formatConfig.Types,
+			formatConfig.Scopes,
+			formatConfig.Parse,
+			formatConfig.Render,
+		)
+		if err == nil {
+			_, err = commit.RegisterFormat(format)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: skipping commit format %q: %v\n", AppName, name, err)
+		}
+	}
+
+	return nil
+}
+
+// commitTypeValue is the value of the --type flag. The name is only resolved
+// to a commit type by resolveCommitType, after the flags are parsed, so the
+// configuration with the user-defined formats isn't loaded before.
+type commitTypeValue struct {
+	name string
+	t    *commit.Type
+}
+
+func (v *commitTypeValue) String() string {
+	if v.name != "" {
+		return v.name
+	}
+	return v.t.ToString()
+}
+
+func (v *commitTypeValue) Set(s string) error {
+	v.name = strings.TrimSpace(s)
+	return nil
+}
+
+func (v *commitTypeValue) Type() string {
+	return "type"
+}
+
+// addCommitTypeFlag adds the flag selecting the commit type to a command
+func addCommitTypeFlag(cmd *cobra.Command, t *commit.Type, usage string) {
+	cmd.Flags().VarP(&commitTypeValue{t: t}, "type", "t", usage)
+}
+
+// resolveCommitType returns the commit type selected with the --type flag,
+// or the default type when the flag isn't set. The user-defined formats of the
+// configuration are registered first, so they can be selected by name.
+func resolveCommitType(cmd *cobra.Command, t commit.Type) (commit.Type, error) {
+	flag := cmd.Flags().Lookup("type")
+	if flag == nil || !flag.Changed {
+		return t, nil
+	}
+
+	name := flag.Value.String()
+
+	// the built-in types don't need the configuration
+	if resolved, err := commit.ParseType(name); err == nil {
+		return resolved, nil
+	}
+
+	if err := registerCommitFormats(); err != nil {
+		return t, err
+	}
+
+	resolved, err := commit.ParseType(name)
+	if err != nil {
+		names := make([]string, 0, len(commit.TypeIds))
+		for _, ids := range commit.TypeIds {
+			names = append(names, ids...)
+		}
+		sort.Strings(names)
+
+		return t, fmt.Errorf("Unknown commit type %q, must be one of: %s", name, strings.Join(names, ", ")) //nolint:staticcheck
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zbiljic/gitexec"
+
+	"github.com/zbiljic/kai/internal/config"
+)
+
+// commitOptions are the options of the commits created by kai, passed through
+// to git.
+type commitOptions struct {
+	Signoff  bool
+	GpgSign  gpgSignValue
+	NoVerify bool
+	Author   string
+	Date     string
+}
+
+// gitCommitOptions returns the gitexec options of a commit in the directory
+// with the options.
+func (o commitOptions) gitCommitOptions(workDir string) *gitexec.CommitOptions {
+	return &gitexec.CommitOptions{
+		CmdDir:   workDir,
+		Signoff:  o.Signoff,
+		GpgSign:  o.GpgSign.keyID,
+		NoVerify: o.NoVerify,
+		Author:   o.Author,
+		Date:     o.Date,
+	}
+}
+
+// gpgSignValue is the value of the --gpg-sign flag: signing with the default
+// key, with a key ID, or not signing.
+type gpgSignValue struct {
+	sign  bool
+	keyID string
+}
+
+func (v *gpgSignValue) String() string {
+	if v.keyID != "" {
+		return v.keyID
+	}
+	if v.sign {
+		return "true"
+	}
+	return ""
+}
+
+func (v *gpgSignValue) Set(s string) error {
+	switch s = strings.TrimSpace(s); strings.ToLower(s) {
+	case "", "false":
+		*v = gpgSignValue{}
+	case "true":
+		*v = gpgSignValue{sign: true}
+	default:
+		*v = gpgSignValue{sign: true, keyID: s}
+	}
+	return nil
+}
+
+func (v *gpgSignValue) Type() string {
+	return "keyid"
+}
+
+// env returns the environment of the git commands signing with the default
+// key. The gitexec options only pass --gpg-sign with a key ID, so the default
+// key is requested with the commit.gpgSign configuration, which 'git commit'
+// and 'git rebase' both honor, after the configuration set in the environment
+// of kai.
+func (v gpgSignValue) env() []string {
+	if !v.sign || v.keyID != "" {
+		return nil
+	}
+
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=commit.gpgSign", count),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=true", count),
+	}
+}
+
+// addCommitSignFlags adds the flags signing the commits to a command
+func addCommitSignFlags(cmd *cobra.Command, opts *commitOptions) {
+	cmd.Flags().BoolVarP(&opts.Signoff, "signoff", "s", false, "Add a Signed-off-by trailer to the commit messages")
+	cmd.Flags().VarP(&opts.GpgSign, "gpg-sign", "S", "GPG or SSH sign the commits, with the default key or the given key ID")
+	cmd.Flags().Lookup("gpg-sign").NoOptDefVal = "true"
+}
+
+// addCommitVerifyFlag adds the flag bypassing the commit hooks to a command
+func addCommitVerifyFlag(cmd *cobra.Command, opts *commitOptions) {
+	cmd.Flags().BoolVar(&opts.NoVerify, "no-verify", false, "Bypass the pre-commit and commit-msg hooks")
+}
+
+// addCommitFlags adds the flags passed through to the created commits to a
+// command
+func addCommitFlags(cmd *cobra.Command, opts *commitOptions) {
+	addCommitSignFlags(cmd, opts)
+	addCommitVerifyFlag(cmd, opts)
+	cmd.Flags().StringVar(&opts.Author, "author", "", "Override the commit author, in the 'Name <email>' format")
+	cmd.Flags().StringVar(&opts.Date, "date", "", "Override the author date of the commits")
+}
+
+// resolveCommitOptions returns the commit options of the flags, with the
+// defaults of the configuration for the flags which aren't set. The date has
+// no default, a configured date would be the same for every commit.
+func resolveCommitOptions(cmd *cobra.Command, opts commitOptions) (commitOptions, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return opts, err
+	}
+
+	if cfg.Commit == nil {
+		return opts, nil
+	}
+
+	changed := func(name string) bool {
+		return cmd.Flags().Lookup(name) == nil || cmd.Flags().Changed(name)
+	}
+
+	if !changed("signoff") {
+		opts.Signoff = cfg.Commit.Signoff
+	}
+	if !changed("gpg-sign") {
+		if err := opts.GpgSign.Set(cfg.Commit.GpgSign); err != nil {
+			return opts, err
+		}
+	}
+	if !changed("no-verify") {
+		opts.NoVerify = cfg.Commit.NoVerify
+	}
+	if !changed("author") {
+		opts.Author = cfg.Commit.Author
+	}
+
+	return opts, nil
+}
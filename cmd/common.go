@@ -3,8 +3,11 @@ package cmd
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/zbiljic/kai/pkg/commit"
 )
 
 type (
@@ -25,3 +28,18 @@ func setupGitWorkDir() (string, error) {
 	}
 	return workDir, nil
 }
+
+// loadCommitRules returns the commit message validation rules for the commit
+// type, applying the repository's commitlint configuration if there is one.
+func loadCommitRules(workDir string, commitType commit.Type) (commit.Rules, error) {
+	rules, _, err := commit.LoadCommitlintRules(workDir, commit.DefaultRules(commitType))
+	if err != nil {
+		return commit.Rules{}, err
+	}
+	return rules, nil
+}
+
+// shellQuote quotes the string for use as a single word in a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
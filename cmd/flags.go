@@ -1,12 +1,37 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/thediveo/enumflag/v2"
+
+	"github.com/zbiljic/kai/internal/config"
 )
 
 // addCommonLLMFlags adds the common LLM provider and model flags to a command
 func addCommonLLMFlags(cmd *cobra.Command, provider *ProviderType, model *string) {
-	cmd.Flags().VarP(enumflag.New(provider, "provider", ProviderIds, enumflag.EnumCaseInsensitive), "provider", "p", "LLM provider to use (phind, openai, claude, googleai, openrouter, groq, deepseek)")
+	cmd.Flags().VarP(enumflag.New(provider, "provider", ProviderIds, enumflag.EnumCaseInsensitive), "provider", "p", "LLM provider to use (phind, openai, claude, googleai, openrouter, groq, deepseek, offline)")
 	cmd.Flags().StringVarP(model, "model", "m", "", "Specific model to use for the selected provider")
 }
+
+// addLangFlag adds the flag selecting the language of the generated text to a
+// command
+func addLangFlag(cmd *cobra.Command, lang *string) {
+	cmd.Flags().StringVar(lang, "lang", "", "Language of the generated text, e.g. German (types, scopes and template headings stay unchanged)")
+}
+
+// resolveLang returns the language of the --lang flag when it is set, or else
+// the language configured for the agent of the command
+func resolveLang(cmd *cobra.Command, agent, lang string) (string, error) {
+	if cmd.Flags().Changed("lang") {
+		return strings.TrimSpace(lang), nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(cfg.Agents[agent].Lang), nil
+}
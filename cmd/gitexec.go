@@ -2,26 +2,57 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/duke-git/lancet/v2/slice"
 	"github.com/zbiljic/gitexec"
+
+	"github.com/zbiljic/kai/internal/config"
+	"github.com/zbiljic/kai/pkg/gitdiff"
+	"github.com/zbiljic/kai/pkg/history"
 )
 
-var (
-	filesToExclude = []string{
-		"*.lock*", // yarn.lock, Cargo.lock, Gemfile.lock, Pipfile.lock, etc.
-		"go*.sum",
-		"package-lock.json",
-		"pnpm-lock.yaml",
+// gitEmptyTree is the hash of the empty tree object.
+const gitEmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// filesToExclude are the patterns of the files always left out of the diffs,
+// in addition to the patterns of the configuration and of the .kaiignore file.
+var filesToExclude = []string{
+	"*.lock*", // yarn.lock, Cargo.lock, Gemfile.lock, Pipfile.lock, etc.
+	"go*.sum",
+	"package-lock.json",
+	"pnpm-lock.yaml",
+}
+
+// gitDiffIgnoreFile is the file with the gitignore-syntax patterns of the
+// files left out of the diffs, at the root of the repository.
+const gitDiffIgnoreFile = ".kaiignore"
+
+// gitDiffIgnore returns the patterns of the files left out of the diffs: the
+// lockfiles, the patterns of the configuration and of the .kaiignore file, in
+// increasing precedence.
+func gitDiffIgnore(workDir string) (*gitdiff.Ignore, error) {
+	ignore := gitdiff.NewIgnore(filesToExclude...)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
 	}
+	ignore.Add(cfg.DiffExclude...)
 
-	excludeFromDiff = slice.FlatMap(filesToExclude, func(i int, s string) []string {
-		return []string{":(exclude)" + s}
-	})
-)
+	content, err := os.ReadFile(filepath.Join(workDir, gitDiffIgnoreFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", gitDiffIgnoreFile, err)
+	}
+	ignore.Add(strings.Split(string(content), "\n")...)
+
+	return ignore, nil
+}
 
 func gitWorkingTreeDir(path string) (string, error) {
 	out, err := gitexec.RevParse(&gitexec.RevParseOptions{
@@ -35,52 +66,248 @@ func gitWorkingTreeDir(path string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-func gitDiffStaged(path string) ([]string, string, error) {
-	out, err := gitexec.Diff(&gitexec.DiffOptions{
-		CmdDir:   path,
-		Cached:   true,
-		Minimal:  true,
-		NameOnly: true,
-		Path:     excludeFromDiff,
+// gitHooksDir returns the directory git runs the hooks from, which respects
+// core.hooksPath.
+func gitHooksDir(workDir string) (string, error) {
+	dir, err := gitPath(workDir, "hooks")
+	if err != nil {
+		return "", fmt.Errorf("failed to get hooks directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// gitPath resolves the path of a file in the git directory, e.g. "MERGE_HEAD".
+func gitPath(workDir, name string) (string, error) {
+	out, err := gitexec.RevParse(&gitexec.RevParseOptions{
+		CmdDir: workDir,
+		Arg:    []string{"--git-path", name},
 	})
 	if err != nil {
-		return []string{}, "", err
+		return "", err
+	}
+
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+
+	return path, nil
+}
+
+// gitDiffStaged returns the staged files and diff, and the staged files left
+// out of the diff.
+func gitDiffStaged(path string) ([]string, string, []gitdiff.FileStat, error) {
+	return gitDiffChanges(path, true, "", nil)
+}
+
+// gitDiffUnstaged returns the changed files and the diff of the changes in
+// the working tree that aren't staged.
+func gitDiffUnstaged(path string) ([]string, string, []gitdiff.FileStat, error) {
+	return gitDiffChanges(path, false, "", nil)
+}
+
+// gitDiffStagedPaths returns the staged files and diff limited to the pathspecs.
+func gitDiffStagedPaths(path string, pathspecs []string) ([]string, string, []gitdiff.FileStat, error) {
+	return gitDiffChanges(path, true, "", pathspecs)
+}
+
+// gitDiffUnstagedPaths returns the unstaged files and diff limited to the
+// pathspecs.
+func gitDiffUnstagedPaths(path string, pathspecs []string) ([]string, string, []gitdiff.FileStat, error) {
+	return gitDiffChanges(path, false, "", pathspecs)
+}
+
+// gitDiffAmend returns the files and diff of the staged changes against the
+// parent of HEAD, which is what 'git commit --amend' commits. Without parents
+// HEAD is a root commit and the diff is against the empty tree.
+func gitDiffAmend(path string, parents []string) ([]string, string, []gitdiff.FileStat, error) {
+	parent := gitEmptyTree
+	if len(parents) > 0 {
+		parent = parents[0]
+	}
+	return gitDiffChanges(path, true, parent, nil)
+}
+
+// gitDiffWorkingTreePaths returns the files and diff of the working tree
+// against HEAD limited to the pathspecs, which is what 'git commit -- <paths>'
+// commits.
+func gitDiffWorkingTreePaths(path string, pathspecs []string) ([]string, string, []gitdiff.FileStat, error) {
+	return gitDiffChanges(path, false, "HEAD", pathspecs)
+}
+
+// gitDiffChanges returns the changed files and their diff, leaving out the
+// files matching the diff ignore patterns or marked as linguist-generated in
+// .gitattributes. The files left out are returned with their line counts, so
+// they can still be mentioned.
+func gitDiffChanges(path string, cached bool, commit string, pathspecs []string) ([]string, string, []gitdiff.FileStat, error) {
+	stats, err := gitDiffNumstat(path, cached, commit, pathspecs)
+	if err != nil {
+		return []string{}, "", nil, err
 	}
 
-	outString := strings.TrimSpace(string(out))
-	if outString == "" {
-		return []string{}, "", nil
+	if len(stats) == 0 {
+		return []string{}, "", nil, nil
 	}
 
-	files := strings.Split(outString, "\n")
+	ignore, err := gitDiffIgnore(path)
+	if err != nil {
+		return []string{}, "", nil, err
+	}
 
-	out, err = gitexec.Diff(&gitexec.DiffOptions{
+	generated, err := gitLinguistGenerated(path, slice.Map(stats, func(_ int, s gitdiff.FileStat) string { return s.Path }))
+	if err != nil {
+		return []string{}, "", nil, err
+	}
+
+	var (
+		files    []string
+		excluded []gitdiff.FileStat
+		paths    = append([]string{}, pathspecs...)
+	)
+	for _, stat := range stats {
+		if !ignore.Match(stat.Path) && !generated[stat.Path] {
+			files = append(files, stat.Path)
+			continue
+		}
+
+		excluded = append(excluded, stat)
+		paths = append(paths, ":(exclude,literal)"+stat.Path)
+		if stat.OldPath != "" {
+			paths = append(paths, ":(exclude,literal)"+stat.OldPath)
+		}
+	}
+
+	if len(files) == 0 {
+		return []string{}, "", excluded, nil
+	}
+
+	out, err := gitexec.Diff(&gitexec.DiffOptions{
 		CmdDir:  path,
-		Cached:  true,
+		Cached:  cached,
+		Commit:  commit,
 		Minimal: true,
-		Path:    excludeFromDiff,
+		Path:    paths,
 	})
 	if err != nil {
-		return []string{}, "", err
+		return []string{}, "", nil, err
 	}
 
 	diff := strings.TrimSpace(string(out))
 
-	return files, diff, nil
+	return files, diff, excluded, nil
 }
 
-func gitCommit(path, message string) error {
-	_, err := gitexec.Commit(&gitexec.CommitOptions{
-		CmdDir:  path,
-		Message: message,
-	})
+// gitDiffNumstat returns the changed files with the number of added and
+// deleted lines.
+func gitDiffNumstat(workDir string, cached bool, commit string, pathspecs []string) ([]gitdiff.FileStat, error) {
+	// the standard output only, warnings would break the NUL separated fields
+	output, err := gitexec.DiffCmd(&gitexec.DiffOptions{
+		CmdDir:  workDir,
+		Cached:  cached,
+		Numstat: true,
+		Z:       true,
+		Commit:  commit,
+		Path:    append([]string{"--"}, pathspecs...),
+	}).Output()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+
+	return gitdiff.ParseNumstat(string(output))
+}
+
+// gitLinguistGenerated returns the files marked as generated with the
+// linguist-generated attribute in .gitattributes.
+func gitLinguistGenerated(workDir string, files []string) (map[string]bool, error) {
+	cmd := gitCmd(workDir, "check-attr", "-z", "--stdin", "linguist-generated")
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\x00") + "\x00")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check attributes: %w", err)
+	}
+
+	// the output is a sequence of <path> NUL <attribute> NUL <value> NUL
+	generated := make(map[string]bool)
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if value := fields[i+2]; value == "set" || value == "true" {
+			generated[fields[i]] = true
+		}
+	}
+
+	return generated, nil
+}
+
+func gitCommit(path, message string, opts commitOptions) error {
+	commitOpts := opts.gitCommitOptions(path)
+	commitOpts.Message = message
+
+	return gitRunCommit(commitOpts, opts)
+}
+
+// gitCommitPaths commits only the working tree contents of the pathspecs,
+// leaving other staged changes in the index, like 'git commit -- <paths>'.
+func gitCommitPaths(path, message string, pathspecs []string, opts commitOptions) error {
+	commitOpts := opts.gitCommitOptions(path)
+	commitOpts.Message = message
+	commitOpts.Only = true
+	commitOpts.DoNotInterpretMoreArgumentsAsOptions = true
+	commitOpts.Pathspec = pathspecs
+
+	return gitRunCommit(commitOpts, opts)
+}
+
+// gitCommitAmend replaces the HEAD commit with the staged changes and the
+// message.
+func gitCommitAmend(path, message string, opts commitOptions) error {
+	commitOpts := opts.gitCommitOptions(path)
+	commitOpts.Message = message
+	commitOpts.Amend = true
+
+	return gitRunCommit(commitOpts, opts)
+}
+
+// gitRunCommit runs 'git commit' with the gitexec options, in the environment
+// of the commit options.
+func gitRunCommit(commitOpts *gitexec.CommitOptions, opts commitOptions) error {
+	cmd := gitexec.CommitCmd(commitOpts)
+	cmd.Env = append(os.Environ(), opts.GpgSign.env()...)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit: %w\n%s", err, strings.TrimSpace(string(output)))
 	}
 
 	return nil
 }
 
+// gitHeadOnUpstream reports whether HEAD is already part of the upstream
+// branch of the current branch, and returns the upstream branch.
+func gitHeadOnUpstream(workDir string) (string, bool) {
+	out, err := gitexec.RevParse(&gitexec.RevParseOptions{
+		CmdDir: workDir,
+		Arg:    []string{"--abbrev-ref", "--symbolic-full-name", "@{upstream}"},
+	})
+	if err != nil {
+		return "", false
+	}
+	upstream := strings.TrimSpace(string(out))
+
+	// commits on the upstream branch aren't listed in upstream..HEAD
+	out, err = gitexec.Log(&gitexec.LogOptions{
+		CmdDir:   workDir,
+		Paths:    upstream + "..HEAD",
+		Format:   "%H",
+		MaxCount: 1,
+	})
+	if err != nil {
+		return upstream, false
+	}
+
+	return upstream, strings.TrimSpace(string(out)) == ""
+}
+
 func gitAddAll(path string) error {
 	_, err := gitexec.Add(&gitexec.AddOptions{
 		CmdDir: path,
@@ -163,75 +390,51 @@ func gitStagedFiles(workDir string) ([]string, error) {
 	return stagedFiles, nil
 }
 
-// gitPreviousCommitMessages returns previous commit messages for the specified files.
-func gitPreviousCommitMessages(workDir string, files []string, maxCommits int) ([]string, error) {
-	if len(files) == 0 {
+// gitHistoryCommits returns the latest commits, without merges, changing the
+// paths, with the files each of them changed, in a single 'git log' pass.
+func gitHistoryCommits(workDir string, paths []string, maxCommits int) ([]history.Commit, error) {
+	if len(paths) == 0 {
 		return nil, nil
 	}
 
-	opts := &gitexec.LogOptions{
-		CmdDir:   workDir,
-		MaxCount: maxCommits,
-		Format:   "%s", // subject only
-		Path:     files,
-	}
+	// the gitexec log options have neither --name-only nor --no-renames
+	args := history.LogArgs(paths, maxCommits)
 
-	output, err := gitexec.Log(opts)
+	output, err := gitexec.Command(workDir, args[0], args[1:]...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get commit history: %w\n%s", err, strings.TrimSpace(string(output)))
 	}
 
-	if len(output) == 0 {
-		return nil, nil
-	}
-
-	// Split by newlines and filter out empty messages
-	messages := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var result []string
-
-	for _, msg := range messages {
-		if msg != "" {
-			result = append(result, msg)
-		}
-	}
-
-	return result, nil
+	return history.ParseLog(string(output)), nil
 }
 
-// gitPreviousCommitMessagesForDirectories returns previous commit messages for the specified directories.
-func gitPreviousCommitMessagesForDirectories(workDir string, directories []string, maxCommits int) ([]string, error) {
-	if len(directories) == 0 {
-		return nil, nil
-	}
-
-	opts := &gitexec.LogOptions{
-		CmdDir:   workDir,
-		MaxCount: maxCommits,
-		Format:   "%s", // subject only
-		Path:     directories,
-	}
-
-	output, err := gitexec.Log(opts)
+// gitUserEmail returns the configured email of the user, or an empty string
+// when it is not set.
+func gitUserEmail(workDir string) string {
+	output, err := gitexec.Command(workDir, "config", "user.email")
 	if err != nil {
-		return nil, err
-	}
-
-	if len(output) == 0 {
-		return nil, nil
+		return ""
 	}
 
-	// Split by newlines and filter out empty messages
-	messages := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var result []string
+	return strings.TrimSpace(string(output))
+}
 
-	for _, msg := range messages {
-		trimmed := strings.TrimSpace(msg)
-		if trimmed != "" {
-			result = append(result, trimmed)
+// gitCommentChar returns the string starting the comment lines of the commit
+// messages, from core.commentString or core.commentChar. With "auto" git picks
+// a character the prepared message doesn't start a line with, which is "#"
+// for the messages kai prepares.
+func gitCommentChar(workDir string) string {
+	for _, key := range []string{"core.commentString", "core.commentChar"} {
+		output, err := gitexec.Command(workDir, "config", key)
+		if err != nil {
+			continue
+		}
+		if commentChar := strings.TrimRight(string(output), "\n"); commentChar != "" && commentChar != "auto" {
+			return commentChar
 		}
 	}
 
-	return result, nil
+	return "#"
 }
 
 // gitLastCommitForFile returns the last commit hash that modified the given file.
@@ -299,27 +502,47 @@ func gitUnstageAll(workDir string) error {
 	return nil
 }
 
-// gitStageFiles stages the specified files in the given directory.
-func gitStageFiles(workDir string, files []string) error {
-	_, err := gitexec.Add(&gitexec.AddOptions{
+// gitUntrackedFiles returns the untracked files matching the pathspecs, which
+// aren't ignored.
+func gitUntrackedFiles(workDir string, pathspecs []string) ([]string, error) {
+	output, err := gitexec.Command(workDir, "ls-files", append([]string{"--others", "--exclude-standard", "-z", "--"}, pathspecs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get untracked files: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// gitIntentToAdd records that the untracked files will be added, like 'git
+// add -N', so they are part of the diffs of the working tree without staging
+// their content.
+func gitIntentToAdd(workDir string, files []string) error {
+	output, err := gitexec.Add(&gitexec.AddOptions{
 		CmdDir:                               workDir,
+		IntentToAdd:                          true,
 		DoNotInterpretMoreArgumentsAsOptions: true,
 		Pathspec:                             files,
 	})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to add untracked files: %w\n%s", err, strings.TrimSpace(string(output)))
 	}
+
 	return nil
 }
 
-// gitCreateFixupCommit creates a fixup commit for the specified commit hash.
-func gitCreateFixupCommit(workDir, commitHash string) error {
-	_, err := gitexec.Commit(&gitexec.CommitOptions{
+// gitUnstageFiles removes the specified files from the staging area.
+func gitUnstageFiles(workDir string, files []string) error {
+	_, err := gitexec.Reset(&gitexec.ResetOptions{
 		CmdDir:   workDir,
-		Fixup:    commitHash,
-		NoEdit:   true,
-		NoVerify: true,
 		Quiet:    true,
+		Pathspec: append([]string{"--"}, files...),
 	})
 	if err != nil {
 		return err
@@ -327,6 +550,31 @@ func gitCreateFixupCommit(workDir, commitHash string) error {
 	return nil
 }
 
+// gitStageFiles stages the specified files in the given directory.
+func gitStageFiles(workDir string, files []string) error {
+	_, err := gitexec.Add(&gitexec.AddOptions{
+		CmdDir:                               workDir,
+		DoNotInterpretMoreArgumentsAsOptions: true,
+		Pathspec:                             files,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// gitCreateFixupCommit creates a fixup commit for the specified commit hash.
+// The hooks are always skipped for fixups.
+func gitCreateFixupCommit(workDir, commitHash string, opts commitOptions) error {
+	opts.NoVerify = true
+	commitOpts := opts.gitCommitOptions(workDir)
+	commitOpts.Fixup = commitHash
+	commitOpts.NoEdit = true
+	commitOpts.Quiet = true
+
+	return gitRunCommit(commitOpts, opts)
+}
+
 // gitCurrentBranch returns the name of the current branch.
 func gitCurrentBranch(workDir string) (string, error) {
 	out, err := gitexec.SymbolicRef(&gitexec.SymbolicRefOptions{
@@ -399,41 +647,38 @@ func gitFindOldestFixupParent(workDir string, fixupCommits map[string][]string)
 }
 
 // gitDebugRebaseAutosquash returns the command that would be run by gitRebaseAutosquash.
-func gitDebugRebaseAutosquash(workDir, upstream string) string {
-	opts := &gitexec.RebaseOptions{
-		CmdDir:     workDir,
-		Autosquash: true,
-		Autostash:  true,
-		NoVerify:   true,
-		NoGpgSign:  true,
-		Quiet:      true,
-	}
+func gitDebugRebaseAutosquash(workDir, upstream string, opts commitOptions) string {
+	return gitRebaseAutosquashCmd(workDir, upstream, opts).String()
+}
 
-	if upstream != "" {
-		opts.Upstream = upstream
+// gitRebaseAutosquash runs git rebase with autosquash and other recommended options.
+// If upstream is empty, it will use the current branch. The rewritten commits
+// are only signed when requested by the commit options.
+func gitRebaseAutosquash(workDir, upstream string, opts commitOptions) error {
+	cmd := gitRebaseAutosquashCmd(workDir, upstream, opts)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to rebase: %w\n%s", err, strings.TrimSpace(string(output)))
 	}
 
-	return gitexec.RebaseCmd(opts).String()
+	return nil
 }
 
-// gitRebaseAutosquash runs git rebase with autosquash and other recommended options.
-// If upstream is empty, it will use the current branch.
-func gitRebaseAutosquash(workDir, upstream string) error {
-	opts := &gitexec.RebaseOptions{
+func gitRebaseAutosquashCmd(workDir, upstream string, opts commitOptions) *exec.Cmd {
+	cmd := gitexec.RebaseCmd(&gitexec.RebaseOptions{
 		CmdDir:     workDir,
 		Autosquash: true,
 		Autostash:  true,
 		NoVerify:   true,
-		NoGpgSign:  true,
 		Quiet:      true,
-	}
-
-	if upstream != "" {
-		opts.Upstream = upstream
-	}
+		Signoff:    opts.Signoff,
+		GpgSign:    opts.GpgSign.keyID,
+		NoGpgSign:  !opts.GpgSign.sign,
+		Upstream:   upstream,
+	})
+	cmd.Env = append(os.Environ(), opts.GpgSign.env()...)
 
-	_, err := gitexec.Rebase(opts)
-	return err
+	return cmd
 }
 
 // gitResetHard performs a hard reset to the specified ref
@@ -578,21 +823,88 @@ func gitGetCommitsBetweenBranches(workDir, baseBranch string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// gitGetDiffBetweenBranches returns the diff between current branch and base branch
-func gitGetDiffBetweenBranches(workDir, baseBranch string) (string, error) {
-	opts := &gitexec.DiffOptions{
-		CmdDir:  workDir,
-		Minimal: true,
-		Commit:  baseBranch,
-		Path:    excludeFromDiff,
+// gitCommitMessage is a commit hash with its parents, committer and full
+// message.
+type gitCommitMessage struct {
+	Hash           string
+	Parents        []string
+	CommitterName  string
+	CommitterEmail string
+	CommitterDate  string
+	Message        string
+}
+
+// gitCommitMessageFormat is the log format parsed by gitParseCommitMessages.
+const gitCommitMessageFormat = "%H%x1f%P%x1f%cn%x1f%ce%x1f%cI%x1f%B%x1e"
+
+// gitCommitMessages returns the commits in the revision range, newest first.
+// A single revision (without "..") returns only that commit.
+func gitCommitMessages(workDir, revisionRange string) ([]gitCommitMessage, error) {
+	opts := &gitexec.LogOptions{
+		CmdDir: workDir,
+		Paths:  revisionRange,
+		Format: gitCommitMessageFormat,
+	}
+	if !strings.Contains(revisionRange, "..") {
+		opts.MaxCount = 1
 	}
 
-	output, err := gitexec.Diff(opts)
+	output, err := gitexec.Log(opts)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to get commits for '%s': %w", revisionRange, err)
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	return gitParseCommitMessages(output), nil
+}
+
+// gitCommitsTouchingFiles returns the last commits which changed any of the
+// files, newest first.
+func gitCommitsTouchingFiles(workDir string, files []string, maxCommits int) ([]gitCommitMessage, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	output, err := gitexec.Log(&gitexec.LogOptions{
+		CmdDir:   workDir,
+		MaxCount: maxCommits,
+		Format:   gitCommitMessageFormat,
+		Path:     files,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits of the files: %w", err)
+	}
+
+	return gitParseCommitMessages(output), nil
+}
+
+func gitParseCommitMessages(output []byte) []gitCommitMessage {
+	var commits []gitCommitMessage
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 6)
+		if len(fields) != 6 {
+			continue
+		}
+		commits = append(commits, gitCommitMessage{
+			Hash:           fields[0],
+			Parents:        strings.Fields(fields[1]),
+			CommitterName:  fields[2],
+			CommitterEmail: fields[3],
+			CommitterDate:  fields[4],
+			Message:        strings.TrimSpace(fields[5]),
+		})
+	}
+
+	return commits
+}
+
+// gitGetDiffBetweenBranches returns the diff between current branch and base branch
+func gitGetDiffBetweenBranches(workDir, baseBranch string) (string, []gitdiff.FileStat, error) {
+	_, diff, excluded, err := gitDiffChanges(workDir, false, baseBranch, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return diff, excluded, nil
 }
 
 // gitBranchExists checks if a branch exists
@@ -811,3 +1123,477 @@ func isHexString(s string) bool {
 	}
 	return true
 }
+
+// gitRewordCommits rewrites the messages of the given commits, oldest first,
+// in a single rebase stopping at each of them. The commits must be a linear
+// history ending at HEAD. Each commit is amended with its new message and the
+// original committer, so the trees, authors and dates are kept. The trailers
+// of the old messages, e.g. Signed-off-by or Change-Id, are carried over to
+// the new ones. The sign-off, signing and hooks of the commit options only
+// apply to the reworded commits.
+func gitRewordCommits(workDir string, commits []gitCommitMessage, messages map[string]string, opts commitOptions) error {
+	if len(commits) == 0 {
+		return nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kai-reword-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var todo strings.Builder
+	for _, c := range commits {
+		fmt.Fprintf(&todo, "edit %s\n", c.Hash)
+	}
+
+	todoFile := filepath.Join(tmpDir, "git-rebase-todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write rebase todo list: %w", err)
+	}
+
+	var upstream string
+	if parents := commits[0].Parents; len(parents) > 0 {
+		upstream = parents[0]
+	}
+
+	// the todo list is written by copying ours over the one created by git
+	cmd := gitexec.RebaseCmd(&gitexec.RebaseOptions{
+		CmdDir:       workDir,
+		Interactive:  true,
+		Autostash:    true,
+		NoAutosquash: true,
+		Quiet:        true,
+		Root:         upstream == "",
+		Upstream:     upstream,
+	})
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile))
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return gitRewordAbort(workDir, fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output))))
+	}
+
+	for _, c := range commits {
+		if err := gitRewordAmend(workDir, tmpDir, c, messages, opts); err != nil {
+			return gitRewordAbort(workDir, err)
+		}
+
+		// the last continue finishes the rebase
+		cmd := gitexec.RebaseCmd(&gitexec.RebaseOptions{CmdDir: workDir, Continue: true})
+		cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return gitRewordAbort(workDir, fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output))))
+		}
+	}
+
+	return nil
+}
+
+// gitRewordAmend amends the commit the reword rebase stopped at, with its new
+// message when it is reworded. Commits after a reworded one are rewritten too,
+// all of them are amended to keep their committer.
+func gitRewordAmend(workDir, tmpDir string, c gitCommitMessage, messages map[string]string, opts commitOptions) error {
+	commitOpts := &gitexec.CommitOptions{
+		CmdDir:     workDir,
+		Amend:      true,
+		AllowEmpty: true,
+		Quiet:      true,
+		NoEdit:     true,
+		NoVerify:   true,
+	}
+	env := []string{
+		"GIT_COMMITTER_NAME=" + c.CommitterName,
+		"GIT_COMMITTER_EMAIL=" + c.CommitterEmail,
+		"GIT_COMMITTER_DATE=" + c.CommitterDate,
+	}
+
+	if message, ok := messages[c.Hash]; ok {
+		messageFile := filepath.Join(tmpDir, c.Hash+".msg")
+		if err := os.WriteFile(messageFile, []byte(message+"\n"), 0o600); err != nil {
+			return fmt.Errorf("failed to write commit message file: %w", err)
+		}
+		if err := gitCarryTrailers(workDir, tmpDir, c, messageFile); err != nil {
+			return err
+		}
+
+		commitOpts.NoEdit = false
+		commitOpts.File = messageFile
+		commitOpts.Cleanup = "whitespace"
+		commitOpts.NoVerify = opts.NoVerify
+		commitOpts.Signoff = opts.Signoff
+		commitOpts.GpgSign = opts.GpgSign.keyID
+		env = append(env, opts.GpgSign.env()...)
+	}
+
+	cmd := gitexec.CommitCmd(commitOpts)
+	cmd.Env = append(os.Environ(), env...)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to amend %s: %w\n%s", c.Hash, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// gitCarryTrailers adds the trailers of the old message of the commit to the
+// new message in the file, unless the new message already has them.
+func gitCarryTrailers(workDir, tmpDir string, c gitCommitMessage, messageFile string) error {
+	oldMessageFile := filepath.Join(tmpDir, c.Hash+".old.msg")
+	if err := os.WriteFile(oldMessageFile, []byte(c.Message+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write commit message file: %w", err)
+	}
+
+	output, err := gitexec.Command(workDir, "interpret-trailers", "--parse", oldMessageFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse trailers: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	args := []string{"--in-place", "--if-exists", "addIfDifferent"}
+	for _, trailer := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if trailer != "" {
+			args = append(args, "--trailer", trailer)
+		}
+	}
+	if len(args) == 3 {
+		return nil
+	}
+
+	if output, err := gitexec.Command(workDir, "interpret-trailers", append(args, messageFile)...); err != nil {
+		return fmt.Errorf("failed to add trailers: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// gitRewordAbort aborts the reword rebase, restoring the branch, and returns
+// the error of the failed step.
+func gitRewordAbort(workDir string, err error) error {
+	_, _ = gitexec.Rebase(&gitexec.RebaseOptions{CmdDir: workDir, Abort: true})
+
+	return fmt.Errorf("failed to rewrite commit messages: %w", err)
+}
+
+// gitMergeBase returns the best common ancestor of the two commits.
+func gitMergeBase(workDir, a, b string) (string, error) {
+	output, err := gitexec.Command(workDir, "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %s and %s: %w\n%s", a, b, err, strings.TrimSpace(string(output)))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// gitResetSoft moves HEAD to the commit, keeping the index and working tree,
+// so the changes since the commit remain staged.
+func gitResetSoft(workDir, commit string) error {
+	_, err := gitexec.Reset(&gitexec.ResetOptions{
+		CmdDir: workDir,
+		Soft:   true,
+		Commit: commit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", commit, err)
+	}
+
+	return nil
+}
+
+// gitOperation is a git operation which stops for the user to commit, e.g. on
+// conflicts.
+type gitOperation int
+
+const (
+	gitOperationNone gitOperation = iota
+	gitOperationMerge
+	gitOperationRebase
+	gitOperationCherryPick
+	gitOperationRevert
+)
+
+// String returns the git command of the operation.
+func (o gitOperation) String() string {
+	switch o {
+	case gitOperationMerge:
+		return "merge"
+	case gitOperationRebase:
+		return "rebase"
+	case gitOperationCherryPick:
+		return "cherry-pick"
+	case gitOperationRevert:
+		return "revert"
+	default:
+		return ""
+	}
+}
+
+// gitInProgressOperation returns the git operation in progress, detected from
+// the state files git keeps in the git directory.
+func gitInProgressOperation(workDir string) (gitOperation, error) {
+	// a rebase stopped on a conflict has a cherry-pick in progress too
+	states := []struct {
+		name      string
+		operation gitOperation
+	}{
+		{"rebase-merge", gitOperationRebase},
+		{"rebase-apply", gitOperationRebase},
+		{"MERGE_HEAD", gitOperationMerge},
+		{"CHERRY_PICK_HEAD", gitOperationCherryPick},
+		{"REVERT_HEAD", gitOperationRevert},
+	}
+
+	for _, state := range states {
+		path, err := gitPath(workDir, state.name)
+		if err != nil {
+			return gitOperationNone, fmt.Errorf("failed to detect git operation in progress: %w", err)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return state.operation, nil
+		}
+	}
+
+	return gitOperationNone, nil
+}
+
+// gitReadStateFile returns the content of a state file in the git directory,
+// e.g. "MERGE_MSG".
+func gitReadStateFile(workDir, name string) (string, error) {
+	path, err := gitPath(workDir, name)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// gitStagedPaths returns every staged path, including the files without
+// content changes and both paths of renames.
+func gitStagedPaths(workDir string) ([]string, error) {
+	output, err := gitexec.DiffCmd(&gitexec.DiffOptions{
+		CmdDir:    workDir,
+		Cached:    true,
+		NameOnly:  true,
+		NoRenames: true,
+		Z:         true,
+	}).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged paths: %w", err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// gitWriteTree writes the index to a tree object and returns its hash, which
+// keeps the staged content while the index is changed.
+func gitWriteTree(workDir string) (string, error) {
+	output, err := gitexec.Command(workDir, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write the index tree: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// gitReadTree replaces the index with the tree, e.g. to restore the index
+// written by gitWriteTree. The working tree isn't changed.
+func gitReadTree(workDir, tree string) error {
+	if output, err := gitexec.Command(workDir, "read-tree", tree); err != nil {
+		return fmt.Errorf("failed to read tree %s: %w\n%s", tree, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// gitRestore restores the paths in the index as they are in the source, and
+// in the working tree too when worktree is true. Paths which don't exist in the
+// source are removed.
+func gitRestore(workDir, source string, worktree bool, paths []string) error {
+	args := []string{"restore", "--staged", "--source=" + source}
+	if worktree {
+		args = append(args, "--worktree")
+	}
+	args = append(append(args, "--"), paths...)
+
+	if output, err := gitexec.Command(workDir, args[0], args[1:]...); err != nil {
+		return fmt.Errorf("failed to restore paths: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// gitRevParse returns the commit hash of the revision.
+func gitRevParse(workDir, rev string) (string, error) {
+	out, err := gitexec.RevParse(&gitexec.RevParseOptions{
+		CmdDir: workDir,
+		Arg:    []string{"--verify", rev + "^{commit}"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitUnmergedPaths returns the paths which still have conflicts.
+func gitUnmergedPaths(workDir string) ([]string, error) {
+	output, err := gitexec.DiffCmd(&gitexec.DiffOptions{
+		CmdDir:     workDir,
+		NameOnly:   true,
+		DiffFilter: "U",
+		Z:          true,
+	}).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unmerged paths: %w", err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// gitConflictedFiles returns the files with conflicts, as listed by git status.
+func gitConflictedFiles(workDir string) ([]string, error) {
+	// with -z the paths are neither quoted nor escaped
+	output, err := gitexec.StatusCmd(&gitexec.StatusOptions{
+		CmdDir:    workDir,
+		Porcelain: true,
+		Z:         true,
+	}).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	// unmerged entries in porcelain format, e.g. "UU" when both modified
+	unmergedStatuses := []string{"DD", "AU", "UD", "UA", "DU", "AA", "UU"}
+
+	var files []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		if slice.Contain(unmergedStatuses, entry[:2]) {
+			files = append(files, entry[3:])
+		}
+		// renames and copies are followed by their original path
+		if strings.ContainsAny(entry[:2], "RC") {
+			i++
+		}
+	}
+
+	return files, nil
+}
+
+// gitContinueOperation continues the git operation in progress, keeping the
+// commit messages prepared by git.
+func gitContinueOperation(workDir string, operation gitOperation) error {
+	cmd := gitCmd(workDir, operation.String(), "--continue")
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to continue the %s: %w\n%s", operation, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// gitFileContent returns the content of the file at the revision, or in the
+// index when the revision is empty. A file which doesn't exist there, e.g. a
+// new file at HEAD, has no content.
+func gitFileContent(workDir, rev, file string) string {
+	output, err := gitexec.Command(workDir, "show", rev+":"+file)
+	if err != nil {
+		return ""
+	}
+
+	return string(output)
+}
+
+// gitFileChanges returns the changes of the files of the diff, with the sizes
+// of the files, which are shown for binary files and used to find very large
+// files.
+func gitFileChanges(workDir, diff string) []*gitdiff.FileChange {
+	changes := gitdiff.ParseFileChanges(diff)
+
+	var blobs []string
+	for _, c := range changes {
+		if c.OldBlob != "" || c.NewBlob != "" {
+			blobs = append(blobs, c.OldBlob, c.NewBlob)
+		}
+	}
+	if len(blobs) == 0 {
+		return changes
+	}
+
+	sizes := gitBlobSizes(workDir, blobs)
+	for _, c := range changes {
+		if c.OldBlob == "" && c.NewBlob == "" {
+			continue
+		}
+
+		c.OldSize, c.NewSize = sizes[c.OldBlob], sizes[c.NewBlob]
+
+		// the working tree content of unstaged changes isn't in the repository
+		if c.NewSize == 0 && c.Status != "D" {
+			if info, err := os.Stat(filepath.Join(workDir, c.Path)); err == nil {
+				c.NewSize = info.Size()
+			}
+		}
+	}
+
+	return changes
+}
+
+// gitBlobSizes returns the sizes of the blobs in bytes. Missing blobs, like
+// the all-zero hash of added and deleted files, are left out.
+func gitBlobSizes(workDir string, blobs []string) map[string]int64 {
+	cmd := gitCmd(workDir, "cat-file", "--batch-check=%(objectsize)")
+	cmd.Stdin = strings.NewReader(strings.Join(blobs, "\n") + "\n")
+
+	sizes := make(map[string]int64)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return sizes
+	}
+
+	// one line per blob in the input order, "<blob> missing" when not found
+	for i, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if i >= len(blobs) {
+			break
+		}
+		if size, err := strconv.ParseInt(line, 10, 64); err == nil {
+			sizes[blobs[i]] = size
+		}
+	}
+
+	return sizes
+}
+
+// gitCmd returns the git command with the arguments in the directory. It is
+// only used for the subcommands which need an input or an environment, which
+// can't be set with gitexec.Command, as it runs the command itself.
+func gitCmd(workDir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGitRewordCommitsKeepsTrailers(t *testing.T) {
+	dir, git := testGitRepo(t)
+
+	testWriteFile(t, dir, "file.txt", "a\n", 0o644)
+	git("add", "file.txt")
+	git("commit", "-q", "-m", "initial")
+
+	testWriteFile(t, dir, "file.txt", "b\n", 0o644)
+	git("add", "file.txt")
+	git("commit", "-q", "-m", "update file\n\nSigned-off-by: Test <test@example.com>\nChange-Id: I0123456789abcdef")
+
+	testWriteFile(t, dir, "file.txt", "c\n", 0o644)
+	git("add", "file.txt")
+	git("commit", "-q", "-m", "feat: change file again")
+
+	tree := git("rev-parse", "HEAD^{tree}")
+	committerDate := git("log", "-1", "--format=%cI", "HEAD~1")
+
+	commits, err := rewordDetectCommits(dir, "HEAD~2..HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := map[string]string{
+		// the new message has one of the trailers already
+		commits[0].Hash: "fix: update file\n\nChange-Id: I0123456789abcdef",
+	}
+
+	if err := gitRewordCommits(dir, commits, messages, commitOptions{}); err != nil {
+		t.Fatalf("gitRewordCommits() error = %v", err)
+	}
+
+	want := "fix: update file\n\nChange-Id: I0123456789abcdef\nSigned-off-by: Test <test@example.com>\n"
+	if got := git("log", "-1", "--format=%B", "HEAD~1"); strings.TrimSpace(got) != strings.TrimSpace(want) {
+		t.Errorf("reworded message = %q, want %q", got, want)
+	}
+	if got := git("log", "-1", "--format=%cI", "HEAD~1"); got != committerDate {
+		t.Errorf("committer date = %s, want %s", got, committerDate)
+	}
+
+	if got := strings.TrimSpace(git("log", "-1", "--format=%B")); got != "feat: change file again" {
+		t.Errorf("message of the later commit = %q, want it unchanged", got)
+	}
+	if got := git("rev-parse", "HEAD^{tree}"); got != tree {
+		t.Errorf("tree = %s, want %s", got, tree)
+	}
+}
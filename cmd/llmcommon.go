@@ -39,6 +39,8 @@ func initializeLLMProvider(cmdChanged bool, providerType ProviderType, model str
 			return provider.NewDeepSeekProvider(provider.DeepSeekOptions{
 				Model: model,
 			}), nil
+		case OfflineProvider:
+			return provider.NewOfflineProvider(), nil
 		}
 	}
 
@@ -95,3 +97,20 @@ func initializeLLMProvider(cmdChanged bool, providerType ProviderType, model str
 
 	return nil, errors.New("no available LLM providers found - please configure at least one provider's API key")
 }
+
+// initializeCommitLLMProvider initializes the LLM provider generating commit
+// messages. The offline heuristics are used when the requests fail because the
+// network is down. Phind, which is used without an API key when no other
+// provider is configured, falls back to them on any failed request.
+func initializeCommitLLMProvider(cmdChanged bool, providerType ProviderType, model string) (llm.AIPrompt, error) {
+	aip, err := initializeLLMProvider(cmdChanged, providerType, model)
+	if err != nil || cmdChanged {
+		return aip, err
+	}
+
+	if _, ok := aip.(*provider.Phind); ok {
+		return llm.NewFallbackOnError(aip, provider.NewOfflineProvider()), nil
+	}
+
+	return llm.NewFallback(aip, provider.NewOfflineProvider()), nil
+}
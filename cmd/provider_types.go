@@ -22,6 +22,9 @@ const (
 	GroqProvider
 	// DeepSeekProvider represents the DeepSeek provider.
 	DeepSeekProvider
+	// OfflineProvider represents the rule-based offline commit message
+	// generator.
+	OfflineProvider
 )
 
 // ProviderIds maps ProviderType to their string representations.
@@ -33,4 +36,5 @@ var ProviderIds = map[ProviderType][]string{
 	OpenRouterProvider: {"openrouter"},
 	GroqProvider:       {"groq"},
 	DeepSeekProvider:   {"deepseek"},
+	OfflineProvider:    {"offline"},
 }
@@ -10,6 +10,11 @@ type (
 	ProviderConfig = providerConfigV1
 	AgentConfig    = agentConfigV1
 	ModelConfig    = modelConfigV1
+
+	CommitFormatConfig = commitFormatConfigV1
+	ScopeRuleConfig    = scopeRuleConfigV1
+	CheckConfig        = checkConfigV1
+	CommitConfig       = commitConfigV1
 )
 
 // NewDefault creates a new configuration
@@ -9,6 +9,13 @@ type configV1 struct {
 	Model     string                      `json:"model,omitempty"` // global default model
 	Providers map[string]providerConfigV1 `json:"providers"`
 	Agents    map[string]agentConfigV1    `json:"agents,omitempty"`
+
+	CommitFormats map[string]commitFormatConfigV1 `json:"commit_formats,omitempty"`
+
+	DiffExclude []string            `json:"diff_exclude,omitempty"` // gitignore-syntax patterns of files left out of diffs
+	ScopeRules  []scopeRuleConfigV1 `json:"scope_rules,omitempty"`  // scopes of the commits changing the matching paths
+	Check       *checkConfigV1      `json:"check,omitempty"`        // content check of the diffs before they are sent to a model
+	Commit      *commitConfigV1     `json:"commit,omitempty"`       // default options of the commits created by kai
 }
 
 // providerConfigV1 represents a single provider configuration
@@ -32,6 +39,38 @@ type modelConfigV1 struct {
 type agentConfigV1 struct {
 	Model       string `json:"model,omitempty"` // "provider/model-id" format
 	Description string `json:"description,omitempty"`
+	Lang        string `json:"lang,omitempty"` // language of the generated prose, e.g. "German"
+}
+
+// commitFormatConfigV1 represents a user-defined commit message format
+type commitFormatConfigV1 struct {
+	Description string            `json:"description,omitempty"`
+	Format      string            `json:"format"`           // format string for the prompt, e.g. "[<component>] <summary>"
+	Types       map[string]string `json:"types,omitempty"`  // allowed types and their descriptions
+	Scopes      map[string]string `json:"scopes,omitempty"` // allowed scopes and their descriptions
+	Parse       string            `json:"parse"`            // regular expression with named groups: type, scope, breaking, message
+	Render      string            `json:"render"`           // Go template executed with the parsed message
+}
+
+// scopeRuleConfigV1 maps the paths matching a pattern to a commit scope
+type scopeRuleConfigV1 struct {
+	Path  string `json:"path"` // gitignore-syntax pattern, e.g. "services/billing/"
+	Scope string `json:"scope"`
+}
+
+// checkConfigV1 configures the content check of the diffs
+type checkConfigV1 struct {
+	Action      string   `json:"action,omitempty"`        // "redact" (default), "block", "warn" or "off"
+	Disable     []string `json:"disable,omitempty"`       // rules not checked, e.g. "todo"
+	MaxFileSize int64    `json:"max_file_size,omitempty"` // in bytes, from which files are reported as very large
+}
+
+// commitConfigV1 configures the default options of the commits created by kai
+type commitConfigV1 struct {
+	Signoff  bool   `json:"signoff,omitempty"`   // add a Signed-off-by trailer
+	GpgSign  string `json:"gpg_sign,omitempty"`  // "true" to sign with the default key, or the key ID
+	NoVerify bool   `json:"no_verify,omitempty"` // skip the pre-commit and commit-msg hooks
+	Author   string `json:"author,omitempty"`    // "Name <email>"
 }
 
 // newConfigV1 creates a new v1 configuration
@@ -104,5 +143,37 @@ func (c *configV1) validateV1() error {
 		}
 	}
 
+	// Validate commit format configurations
+	for formatName, format := range c.CommitFormats {
+		if format.Format == "" {
+			return fmt.Errorf("commit format '%s' must have a format", formatName)
+		}
+		if format.Parse == "" {
+			return fmt.Errorf("commit format '%s' must have a parse expression", formatName)
+		}
+		if format.Render == "" {
+			return fmt.Errorf("commit format '%s' must have a render template", formatName)
+		}
+	}
+
+	// Validate the content check
+	if c.Check != nil {
+		switch c.Check.Action {
+		case "", "redact", "block", "warn", "off":
+		default:
+			return fmt.Errorf("invalid check action '%s', must be one of redact, block, warn or off", c.Check.Action)
+		}
+	}
+
+	// Validate scope rules
+	for i, rule := range c.ScopeRules {
+		if rule.Path == "" {
+			return fmt.Errorf("scope rule %d must have a path", i+1)
+		}
+		if rule.Scope == "" {
+			return fmt.Errorf("scope rule '%s' must have a scope", rule.Path)
+		}
+	}
+
 	return nil
 }
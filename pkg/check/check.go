@@ -0,0 +1,290 @@
+// Package check scans the added lines of a diff for content which shouldn't
+// be committed, or sent to a model: likely secrets, leftover debug prints,
+// conflict markers, new TODOs and very large files.
+package check
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zbiljic/kai/pkg/gitdiff"
+)
+
+// Rules of the findings.
+const (
+	RuleSecret         = "secret"
+	RuleDebug          = "debug"
+	RuleConflictMarker = "conflict-marker"
+	RuleTodo           = "todo"
+	RuleLargeFile      = "large-file"
+)
+
+// Rules are all the rules, in the order of their severity.
+var Rules = []string{RuleSecret, RuleConflictMarker, RuleDebug, RuleTodo, RuleLargeFile}
+
+// DefaultMaxFileSize is the size from which an added or modified file is
+// reported as very large.
+const DefaultMaxFileSize = 1024 * 1024
+
+// Redacted replaces the secrets redacted from a diff.
+const Redacted = "[REDACTED]"
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError is for findings which must not be committed or sent to a
+	// model, like secrets.
+	SeverityError Severity = "error"
+	// SeverityWarning is for findings which are likely unintended.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is content of the diff flagged by a rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"` // in the new version of the file
+	Match    string   `json:"-"`              // the flagged text, never printed as it may be a secret
+	Message  string   `json:"message"`
+}
+
+// String describes the finding, e.g. "config.go:12 secret: AWS access key".
+func (f Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location += ":" + strconv.Itoa(f.Line)
+	}
+	return fmt.Sprintf("%s %s: %s", location, f.Rule, f.Message)
+}
+
+// Options configure the scan.
+type Options struct {
+	Disable     []string // rules not checked
+	MaxFileSize int64    // defaults to DefaultMaxFileSize
+}
+
+func (o Options) enabled(rule string) bool {
+	return !slices.Contains(o.Disable, rule)
+}
+
+// secretPattern is a known format of a secret. The secret is the first group
+// of the expression, or the whole match without a group.
+type secretPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+var secretPatterns = []secretPattern{
+	{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{"GitHub token", regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"GitLab token", regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})\b`)},
+	{"Slack token", regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})\b`)},
+	{"Google API key", regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`)},
+	{"Stripe key", regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{24,})\b`)},
+	{"API key", regexp.MustCompile(`\b(sk-(?:ant-|proj-)?[A-Za-z0-9_-]{32,})\b`)},
+	{"JSON web token", regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})\b`)},
+}
+
+var (
+	// quotedRegex matches the quoted strings, which may be high-entropy
+	// tokens like generated passwords and keys.
+	quotedRegex = regexp.MustCompile(`["'` + "`" + `]([A-Za-z0-9+/=_\-.~]{20,})["'` + "`" + `]`)
+	// assignmentRegex matches the assignments of secret-like names.
+	assignmentRegex = regexp.MustCompile(`(?i)(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)["']?\s*[:=]`)
+
+	// privateKeyBlockRegex matches a whole private key, also across the lines
+	// of a diff.
+	privateKeyBlockRegex = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY[A-Z ]*-----.*?(?:-----END [A-Z ]*PRIVATE KEY[A-Z ]*-----|$)`)
+
+	conflictMarkerRegex = regexp.MustCompile(`^(?:<{7}|\|{7}|>{7})(?:\s|$)`)
+	todoRegex           = regexp.MustCompile(`\b(TODO|FIXME|XXX|HACK)\b`)
+)
+
+// debugPatterns are the leftover debug prints, by file extension.
+var debugPatterns = map[string]*regexp.Regexp{
+	".go":  regexp.MustCompile(`^\s*(?:println\(|print\(|spew\.(?:Dump|Printf?)\(|pp\.Print(?:ln)?\(|litter\.Dump\()`),
+	".js":  regexp.MustCompile(`\b(?:console\.(?:log|debug|trace|dir)\(|debugger;?\s*$)`),
+	".py":  regexp.MustCompile(`\b(?:breakpoint\(\)|pdb\.set_trace\(\)|import i?pdb\b)`),
+	".rb":  regexp.MustCompile(`\b(?:binding\.(?:pry|irb)|byebug)\b`),
+	".php": regexp.MustCompile(`\b(?:var_dump|print_r|dd|dump)\(`),
+	".rs":  regexp.MustCompile(`\bdbg!\(`),
+}
+
+func init() {
+	for _, ext := range []string{".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".vue", ".svelte"} {
+		debugPatterns[ext] = debugPatterns[".js"]
+	}
+}
+
+// Scan returns the findings in the added lines of the hunks, parsed with
+// gitdiff.ParseDiff, and in the sizes of the changed files.
+func Scan(hunks []*gitdiff.Hunk, changes []*gitdiff.FileChange, opts Options) []Finding {
+	var findings []Finding
+
+	for _, hunk := range hunks {
+		line := hunk.StartLine
+
+		for _, content := range strings.Split(hunk.Content, "\n")[1:] {
+			switch {
+			case strings.HasPrefix(content, "+"):
+				findings = append(findings, scanLine(hunk.FilePath, line, content[1:], opts)...)
+				line++
+			case strings.HasPrefix(content, " "):
+				line++
+			}
+		}
+	}
+
+	if opts.enabled(RuleLargeFile) {
+		maxSize := opts.MaxFileSize
+		if maxSize <= 0 {
+			maxSize = DefaultMaxFileSize
+		}
+
+		for _, c := range changes {
+			if c.Status != "D" && c.NewSize > maxSize && c.NewSize > c.OldSize {
+				findings = append(findings, Finding{
+					Rule:     RuleLargeFile,
+					Severity: SeverityWarning,
+					File:     c.Path,
+					Message:  fmt.Sprintf("very large file (%s)", formatSize(c.NewSize)),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func scanLine(file string, line int, content string, opts Options) []Finding {
+	var findings []Finding
+
+	add := func(rule string, severity Severity, match, message string) {
+		findings = append(findings, Finding{
+			Rule:     rule,
+			Severity: severity,
+			File:     file,
+			Line:     line,
+			Match:    match,
+			Message:  message,
+		})
+	}
+
+	if opts.enabled(RuleSecret) {
+		found := false
+		for _, p := range secretPatterns {
+			if match := p.re.FindStringSubmatch(content); match != nil {
+				add(RuleSecret, SeverityError, match[len(match)-1], p.name)
+				found = true
+			}
+		}
+
+		// a random-looking value, in an assignment of a secret-like name
+		if !found && assignmentRegex.MatchString(content) {
+			for _, match := range quotedRegex.FindAllStringSubmatch(content, -1) {
+				if isHighEntropy(match[1]) {
+					add(RuleSecret, SeverityError, match[1], "high-entropy token")
+				}
+			}
+		}
+	}
+
+	if opts.enabled(RuleConflictMarker) && conflictMarkerRegex.MatchString(content) {
+		add(RuleConflictMarker, SeverityError, content, "conflict marker")
+	}
+
+	if opts.enabled(RuleDebug) {
+		if re, ok := debugPatterns[strings.ToLower(path.Ext(file))]; ok {
+			if match := re.FindString(content); match != "" {
+				add(RuleDebug, SeverityWarning, match, "debug statement: "+strings.TrimSpace(content))
+			}
+		}
+	}
+
+	if opts.enabled(RuleTodo) {
+		if match := todoRegex.FindString(content); match != "" {
+			add(RuleTodo, SeverityWarning, match, "new "+match+": "+strings.TrimSpace(content))
+		}
+	}
+
+	return findings
+}
+
+// isHighEntropy reports whether the token looks random: mixed letters and
+// digits with a high Shannon entropy per character.
+func isHighEntropy(token string) bool {
+	hasLetter := strings.IndexFunc(token, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }) >= 0
+	hasDigit := strings.IndexFunc(token, func(r rune) bool { return r >= '0' && r <= '9' }) >= 0
+	if !hasLetter || !hasDigit {
+		return false
+	}
+
+	return entropy(token) >= 4.0
+}
+
+// entropy returns the Shannon entropy of the string in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var e float64
+	n := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := float64(count) / n
+		e -= p * math.Log2(p)
+	}
+
+	return e
+}
+
+// HasErrors reports whether any finding is an error.
+func HasErrors(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Severity == SeverityError })
+}
+
+// Redact replaces the secrets found in the text, e.g. the diff sent to a
+// model, with Redacted.
+func Redact(text string, findings []Finding) string {
+	for _, f := range findings {
+		if f.Rule == RuleSecret && f.Message == "private key" {
+			// the whole key, not only its header line
+			text = privateKeyBlockRegex.ReplaceAllString(text, Redacted)
+			continue
+		}
+		if f.Rule == RuleSecret && f.Match != "" {
+			text = strings.ReplaceAll(text, f.Match, Redacted)
+		}
+	}
+	return text
+}
+
+// RedactHunks returns copies of the hunks with the secrets redacted from
+// their content, for prompts. The hunks themselves are left intact, so they
+// can still be applied.
+func RedactHunks(hunks []*gitdiff.Hunk, findings []Finding) []*gitdiff.Hunk {
+	redacted := make([]*gitdiff.Hunk, 0, len(hunks))
+	for _, hunk := range hunks {
+		h := *hunk
+		h.Content = Redact(h.Content, findings)
+		h.Context = Redact(h.Context, findings)
+		redacted = append(redacted, &h)
+	}
+	return redacted
+}
+
+func formatSize(size int64) string {
+	if size < 1024*1024 {
+		return fmt.Sprintf("%.1f KiB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%.1f MiB", float64(size)/(1024*1024))
+}
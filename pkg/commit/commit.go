@@ -15,6 +15,9 @@ type Message struct {
 // ToString converts the Message struct into a string representation.
 func (m Message) ToString() string {
 	var out string
+	if IsGitmoji(strings.TrimSpace(m.Type)) {
+		return m.gitmojiString()
+	}
 	if m.Type != "" {
 		if strings.HasSuffix(m.Type, "!") {
 			m.Type = m.Type[:len(m.Type)-1]
@@ -40,6 +43,18 @@ func (m Message) ToString() string {
 	return out
 }
 
+// gitmojiString renders the message in the gitmoji format, keeping the
+// gitmoji as written (shortcode or unicode).
+func (m Message) gitmojiString() string {
+	out := strings.TrimSpace(m.Type)
+	scope := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m.Scope), "!"))
+	if scope != "" {
+		out += fmt.Sprintf(" (%s):", scope)
+	}
+	out += " " + strings.TrimSpace(m.CommitMessage)
+	return out
+}
+
 type Type int
 
 const (
@@ -49,11 +64,15 @@ const (
 	// ConventionalType represents a commit type that adheres to the
 	// conventional commit format.
 	ConventionalType
+	// GitmojiType represents a commit type that starts with a gitmoji,
+	// either as a shortcode or as a unicode emoji.
+	GitmojiType
 )
 
 var TypeIds = map[Type][]string{
 	SimpleType:       {"simple"},
 	ConventionalType: {"conventional"},
+	GitmojiType:      {"gitmoji"},
 }
 
 // ParseType parses a string and returns the corresponding Type.
@@ -85,6 +104,8 @@ var commitTypeFormats = map[Type]string{
 	// ConventionalType follows the conventional commit format where a type
 	// and optional scope are specified.
 	ConventionalType: "<type>(<optional scope>): <commit message>",
+	// GitmojiType prefixes the commit message with a gitmoji shortcode.
+	GitmojiType: "<gitmoji> <commit message>",
 }
 
 // CommitFormat returns the format template associated with the commit type.
@@ -94,3 +115,27 @@ func (t Type) CommitFormat() string {
 	// corresponding entry in the map.
 	return commitTypeFormats[t]
 }
+
+// RevertMessage returns the message of a commit reverting the commit with the
+// given hash and header. The header follows the commit type, and the body
+// references the reverted commit the way git does, followed by the reason if
+// there is one.
+func RevertMessage(t Type, hash, header, reason string) string {
+	var out string
+	switch t {
+	case ConventionalType:
+		out = "revert: " + header
+	case GitmojiType:
+		out = ":rewind: " + header
+	default:
+		out = `Revert "` + header + `"`
+	}
+
+	out += fmt.Sprintf("\n\nThis reverts commit %s.", hash)
+
+	if reason = strings.TrimSpace(reason); reason != "" {
+		out += "\n\n" + reason
+	}
+
+	return out
+}
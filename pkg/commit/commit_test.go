@@ -53,6 +53,23 @@ func TestMessageToString(t *testing.T) {
 			},
 			expected: "refactor!: simplify configuration",
 		},
+		{
+			name: "gitmoji shortcode",
+			message: Message{
+				Type:          ":sparkles:",
+				CommitMessage: "add gitmoji support",
+			},
+			expected: ":sparkles: add gitmoji support",
+		},
+		{
+			name: "gitmoji unicode with scope",
+			message: Message{
+				Type:          "⚡️",
+				Scope:         "api",
+				CommitMessage: "improve performance",
+			},
+			expected: "⚡️ (api): improve performance",
+		},
 	}
 
 	for _, test := range tests {
@@ -64,3 +81,43 @@ func TestMessageToString(t *testing.T) {
 		})
 	}
 }
+
+func TestRevertMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        Type
+		reason   string
+		expected string
+	}{
+		{
+			name:     "conventional",
+			t:        ConventionalType,
+			expected: "revert: feat(api): add endpoint\n\nThis reverts commit abc123.",
+		},
+		{
+			name:     "simple uses git default header",
+			t:        SimpleType,
+			expected: "Revert \"feat(api): add endpoint\"\n\nThis reverts commit abc123.",
+		},
+		{
+			name:     "gitmoji",
+			t:        GitmojiType,
+			expected: ":rewind: feat(api): add endpoint\n\nThis reverts commit abc123.",
+		},
+		{
+			name:     "reason is added after the reference",
+			t:        ConventionalType,
+			reason:   " The endpoint breaks existing clients.\n",
+			expected: "revert: feat(api): add endpoint\n\nThis reverts commit abc123.\n\nThe endpoint breaks existing clients.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := RevertMessage(test.t, "abc123", "feat(api): add endpoint", test.reason)
+			if result != test.expected {
+				t.Errorf("RevertMessage() = %q; want %q", result, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package commit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// commitlintConfigFiles are the JSON commitlint configuration files, in the
+// order commitlint looks them up. JavaScript and YAML configurations are not
+// supported.
+var commitlintConfigFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+}
+
+type commitlintConfig struct {
+	Rules map[string][]json.RawMessage `json:"rules"`
+}
+
+// LoadCommitlintRules looks up a commitlint configuration in dir and applies
+// its rules on top of base. It reports whether a configuration was found.
+func LoadCommitlintRules(dir string, base Rules) (Rules, bool, error) {
+	for _, name := range commitlintConfigFiles {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return base, false, err
+		}
+
+		var cfg commitlintConfig
+		if err := json.Unmarshal(content, &cfg); err != nil {
+			return base, false, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		rules, err := applyCommitlintRules(base, cfg.Rules)
+		if err != nil {
+			return base, false, fmt.Errorf("invalid rules in %s: %w", name, err)
+		}
+		return rules, true, nil
+	}
+
+	// fall back to the "commitlint" key in package.json
+	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return base, false, nil
+		}
+		return base, false, err
+	}
+
+	var pkg struct {
+		Commitlint *commitlintConfig `json:"commitlint"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil || pkg.Commitlint == nil {
+		// not every package.json is meant for us, ignore it
+		return base, false, nil //nolint:nilerr
+	}
+
+	rules, err := applyCommitlintRules(base, pkg.Commitlint.Rules)
+	if err != nil {
+		return base, false, fmt.Errorf("invalid commitlint rules in package.json: %w", err)
+	}
+	return rules, true, nil
+}
+
+// applyCommitlintRules applies rules in the commitlint "[level, condition,
+// value]" format. Rules with level 0 disable the corresponding check.
+func applyCommitlintRules(rules Rules, raw map[string][]json.RawMessage) (Rules, error) {
+	for name, config := range raw {
+		if len(config) == 0 {
+			continue
+		}
+
+		var level int
+		if err := json.Unmarshal(config[0], &level); err != nil {
+			return rules, fmt.Errorf("%s: invalid level: %w", name, err)
+		}
+
+		condition := "always"
+		if len(config) > 1 {
+			if err := json.Unmarshal(config[1], &condition); err != nil {
+				return rules, fmt.Errorf("%s: invalid condition: %w", name, err)
+			}
+		}
+
+		var value json.RawMessage
+		if len(config) > 2 {
+			value = config[2]
+		}
+
+		enabled := level > 0
+		never := condition == "never"
+
+		var err error
+		switch name {
+		case RuleHeaderMaxLength:
+			rules.HeaderMaxLength = 0
+			if enabled {
+				err = json.Unmarshal(value, &rules.HeaderMaxLength)
+			}
+		case RuleTypeEmpty:
+			rules.TypeRequired = enabled && never
+		case RuleTypeEnum:
+			rules.Types = nil
+			if enabled {
+				err = json.Unmarshal(value, &rules.Types)
+			}
+		case RuleScopeEmpty:
+			rules.ScopeRequired = enabled && never
+		case RuleScopeEnum:
+			rules.Scopes = nil
+			if enabled {
+				err = json.Unmarshal(value, &rules.Scopes)
+			}
+		case RuleSubjectFullStop:
+			rules.SubjectFullStop = ""
+			if enabled && never {
+				rules.SubjectFullStop = "."
+				if value != nil {
+					err = json.Unmarshal(value, &rules.SubjectFullStop)
+				}
+			}
+		case RuleSubjectCase:
+			rules.SubjectCase = CaseRule{}
+			if enabled {
+				rules.SubjectCase.Never = never
+				err = unmarshalCases(value, &rules.SubjectCase.Cases)
+			}
+		}
+		if err != nil {
+			return rules, fmt.Errorf("%s: invalid value: %w", name, err)
+		}
+	}
+
+	return rules, nil
+}
+
+// unmarshalCases accepts both a single case and a list of cases.
+func unmarshalCases(value json.RawMessage, cases *[]string) error {
+	var single string
+	if err := json.Unmarshal(value, &single); err == nil {
+		*cases = []string{single}
+		return nil
+	}
+	return json.Unmarshal(value, cases)
+}
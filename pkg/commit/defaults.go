@@ -25,3 +25,109 @@ var ConventionalCommitTypes = map[string]string{
 	"style":    "Changes that do not affect the meaning of the code (white-space, formatting, missing semi-colons, etc)",
 	"test":     "Adding missing tests or correcting existing tests",
 }
+
+/**
+ * References:
+ * Gitmoji:
+ * https://github.com/carloscuesta/gitmoji/blob/master/packages/gitmojis/src/gitmojis.json
+ */
+var GitmojiTypes = map[string]string{
+	":adhesive_bandage:":          "Simple fix for a non-critical issue",
+	":alembic:":                   "Perform experiments",
+	":ambulance:":                 "Critical hotfix",
+	":arrow_down:":                "Downgrade dependencies",
+	":arrow_up:":                  "Upgrade dependencies",
+	":art:":                       "Improve structure / format of the code",
+	":bento:":                     "Add or update assets",
+	":bookmark:":                  "Release / Version tags",
+	":boom:":                      "Introduce breaking changes",
+	":bug:":                       "Fix a bug",
+	":building_construction:":     "Make architectural changes",
+	":bulb:":                      "Add or update comments in source code",
+	":card_file_box:":             "Perform database related changes",
+	":construction:":              "Work in progress",
+	":construction_worker:":       "Add or update CI build system",
+	":coffin:":                    "Remove dead code",
+	":fire:":                      "Remove code or files",
+	":globe_with_meridians:":      "Internationalization and localization",
+	":goal_net:":                  "Catch errors",
+	":green_heart:":               "Fix CI build",
+	":heavy_minus_sign:":          "Remove a dependency",
+	":heavy_plus_sign:":           "Add a dependency",
+	":label:":                     "Add or update types",
+	":lipstick:":                  "Add or update the UI and style files",
+	":lock:":                      "Fix security or privacy issues",
+	":loud_sound:":                "Add or update logs",
+	":mag:":                       "Improve SEO",
+	":memo:":                      "Add or update documentation",
+	":mute:":                      "Remove logs",
+	":package:":                   "Add or update compiled files or packages",
+	":pencil2:":                   "Fix typos",
+	":pushpin:":                   "Pin dependencies to specific versions",
+	":recycle:":                   "Refactor code",
+	":rewind:":                    "Revert changes",
+	":rocket:":                    "Deploy stuff",
+	":rotating_light:":            "Fix compiler / linter warnings",
+	":safety_vest:":               "Add or update code related to validation",
+	":see_no_evil:":               "Add or update a .gitignore file",
+	":sparkles:":                  "Introduce new features",
+	":technologist:":              "Improve developer experience",
+	":tada:":                      "Begin a project",
+	":test_tube:":                 "Add a failing test",
+	":truck:":                     "Move or rename resources (e.g.: files, paths, routes)",
+	":twisted_rightwards_arrows:": "Merge branches",
+	":white_check_mark:":          "Add, update, or pass tests",
+	":wrench:":                    "Add or update configuration files",
+	":zap:":                       "Improve performance",
+}
+
+// gitmojiEmojis maps gitmoji shortcodes to their unicode representation.
+var gitmojiEmojis = map[string]string{
+	":adhesive_bandage:":          "🩹",
+	":alembic:":                   "⚗️",
+	":ambulance:":                 "🚑️",
+	":arrow_down:":                "⬇️",
+	":arrow_up:":                  "⬆️",
+	":art:":                       "🎨",
+	":bento:":                     "🍱",
+	":bookmark:":                  "🔖",
+	":boom:":                      "💥",
+	":bug:":                       "🐛",
+	":building_construction:":     "🏗️",
+	":bulb:":                      "💡",
+	":card_file_box:":             "🗃️",
+	":construction:":              "🚧",
+	":construction_worker:":       "👷",
+	":coffin:":                    "⚰️",
+	":fire:":                      "🔥",
+	":globe_with_meridians:":      "🌐",
+	":goal_net:":                  "🥅",
+	":green_heart:":               "💚",
+	":heavy_minus_sign:":          "➖",
+	":heavy_plus_sign:":           "➕",
+	":label:":                     "🏷️",
+	":lipstick:":                  "💄",
+	":lock:":                      "🔒️",
+	":loud_sound:":                "🔊",
+	":mag:":                       "🔍️",
+	":memo:":                      "📝",
+	":mute:":                      "🔇",
+	":package:":                   "📦️",
+	":pencil2:":                   "✏️",
+	":pushpin:":                   "📌",
+	":recycle:":                   "♻️",
+	":rewind:":                    "⏪️",
+	":rocket:":                    "🚀",
+	":rotating_light:":            "🚨",
+	":safety_vest:":               "🦺",
+	":see_no_evil:":               "🙈",
+	":sparkles:":                  "✨",
+	":technologist:":              "🧑‍💻",
+	":tada:":                      "🎉",
+	":test_tube:":                 "🧪",
+	":truck:":                     "🚚",
+	":twisted_rightwards_arrows:": "🔀",
+	":white_check_mark:":          "✅",
+	":wrench:":                    "🔧",
+	":zap:":                       "⚡️",
+}
@@ -0,0 +1,164 @@
+package commit
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Format is a user-defined commit message format, e.g. "[component] summary"
+// or "JIRA-123: summary".
+type Format struct {
+	// Name is the identifier used to select the format (e.g. with --type).
+	Name string
+	// Description is a short human readable description of the format.
+	Description string
+	// Format is the format string passed to the model in the prompt.
+	Format string
+	// Types is the table of allowed types with their descriptions.
+	Types map[string]string
+	// Scopes is the table of allowed scopes with their descriptions.
+	Scopes map[string]string
+
+	parse  *regexp.Regexp
+	render *template.Template
+}
+
+// NewFormat creates a new commit message format.
+//
+// The parse expression must contain a "message" named group, and may contain
+// "type", "scope" and "breaking" named groups. The render template is executed
+// with a Message value, e.g. "[{{.Scope}}] {{.CommitMessage}}".
+func NewFormat(name, description, format string, types, scopes map[string]string, parse, render string) (*Format, error) {
+	if name == "" {
+		return nil, fmt.Errorf("commit format must have a name")
+	}
+
+	parseRegex, err := regexp.Compile(parse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parse expression for commit format '%s': %w", name, err)
+	}
+
+	if !slices.Contains(parseRegex.SubexpNames(), "message") {
+		return nil, fmt.Errorf("parse expression for commit format '%s' must contain a 'message' named group", name)
+	}
+
+	renderTmpl, err := template.New(name).Option("missingkey=error").Parse(render)
+	if err != nil {
+		return nil, fmt.Errorf("invalid render template for commit format '%s': %w", name, err)
+	}
+
+	return &Format{
+		Name:        name,
+		Description: description,
+		Format:      format,
+		Types:       types,
+		Scopes:      scopes,
+		parse:       parseRegex,
+		render:      renderTmpl,
+	}, nil
+}
+
+// HasType reports whether messages in this format contain a type.
+func (f *Format) HasType() bool {
+	return slices.Contains(f.parse.SubexpNames(), "type")
+}
+
+// HasScope reports whether messages in this format contain a scope.
+func (f *Format) HasScope() bool {
+	return slices.Contains(f.parse.SubexpNames(), "scope")
+}
+
+// Parse parses the message using the format's parse expression. If the message
+// doesn't match, the whole message is returned as the commit message and ok
+// is false.
+func (f *Format) Parse(message string) (Message, bool) {
+	match := f.parse.FindStringSubmatch(message)
+	if len(match) == 0 {
+		return Message{
+			CommitMessage: message,
+		}, false
+	}
+
+	var m Message
+	for i, name := range f.parse.SubexpNames() {
+		switch name {
+		case "type":
+			m.Type = match[i]
+		case "scope":
+			m.Scope = match[i]
+		case "breaking":
+			m.Breaking = match[i] != ""
+		case "message":
+			m.CommitMessage = match[i]
+		}
+	}
+
+	return m, true
+}
+
+// Render renders the message using the format's render template. It falls
+// back to the plain commit message if the template can't be executed.
+func (f *Format) Render(m Message) string {
+	m.Type = strings.TrimSpace(m.Type)
+	m.Scope = strings.TrimSpace(m.Scope)
+	m.CommitMessage = strings.TrimSpace(m.CommitMessage)
+
+	var buf bytes.Buffer
+	if err := f.render.Execute(&buf, m); err != nil {
+		return m.CommitMessage
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// customFormats holds the user-defined formats registered with RegisterFormat.
+var customFormats = map[Type]*Format{}
+
+// RegisterFormat registers a user-defined commit message format and returns
+// the Type assigned to it. The format becomes selectable by its name through
+// TypeIds and ParseType.
+func RegisterFormat(f *Format) (Type, error) {
+	if _, err := ParseType(f.Name); err == nil {
+		return Type(0), fmt.Errorf("commit format '%s' is already defined", f.Name)
+	}
+
+	t := GitmojiType + 1
+	for _, exists := TypeIds[t]; exists; _, exists = TypeIds[t] {
+		t++
+	}
+
+	TypeIds[t] = []string{f.Name}
+	commitTypeFormats[t] = f.Format
+	customFormats[t] = f
+
+	return t, nil
+}
+
+// CustomFormat returns the user-defined format for the Type, if any.
+func (t Type) CustomFormat() (*Format, bool) {
+	f, ok := customFormats[t]
+	return f, ok
+}
+
+// ParseMessage parses the message according to the Type, using the format's
+// parse expression for user-defined formats.
+func (t Type) ParseMessage(message string) Message {
+	if f, ok := t.CustomFormat(); ok {
+		m, _ := f.Parse(message)
+		return m
+	}
+	return ParseMessage(message)
+}
+
+// Render converts the message into its string representation according to
+// the Type, using the format's render template for user-defined formats.
+func (t Type) Render(m Message) string {
+	if f, ok := t.CustomFormat(); ok {
+		return f.Render(m)
+	}
+	return m.ToString()
+}
@@ -0,0 +1,120 @@
+package commit
+
+import "testing"
+
+func TestFormatParseAndRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    string
+		render   string
+		input    string
+		expected Message
+		ok       bool
+		output   string
+	}{
+		{
+			name:     "component prefix",
+			parse:    `^\[(?P<scope>[^\]]+)\] (?P<message>.+)$`,
+			render:   "[{{.Scope}}] {{.CommitMessage}}",
+			input:    "[parser] Handle empty input",
+			expected: Message{Scope: "parser", CommitMessage: "Handle empty input"},
+			ok:       true,
+			output:   "[parser] Handle empty input",
+		},
+		{
+			name:     "issue key prefix",
+			parse:    `^(?P<type>[A-Z]+-\d+): (?P<message>.+)$`,
+			render:   "{{.Type}}: {{.CommitMessage}}",
+			input:    "JIRA-123: fix login redirect",
+			expected: Message{Type: "JIRA-123", CommitMessage: "fix login redirect"},
+			ok:       true,
+			output:   "JIRA-123: fix login redirect",
+		},
+		{
+			name:     "angular with mandatory scope",
+			parse:    `^(?P<type>\w+)\((?P<scope>[^)]+)\)(?P<breaking>!)?: (?P<message>.+)$`,
+			render:   "{{.Type}}({{.Scope}}){{if .Breaking}}!{{end}}: {{.CommitMessage}}",
+			input:    "feat(core)!: drop legacy api",
+			expected: Message{Type: "feat", Scope: "core", Breaking: true, CommitMessage: "drop legacy api"},
+			ok:       true,
+			output:   "feat(core)!: drop legacy api",
+		},
+		{
+			name:     "not matching",
+			parse:    `^\[(?P<scope>[^\]]+)\] (?P<message>.+)$`,
+			render:   "[{{.Scope}}] {{.CommitMessage}}",
+			input:    "handle empty input",
+			expected: Message{CommitMessage: "handle empty input"},
+			ok:       false,
+			output:   "[] handle empty input",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := NewFormat(test.name, "", "", nil, nil, test.parse, test.render)
+			if err != nil {
+				t.Fatalf("NewFormat() error = %v", err)
+			}
+
+			result, ok := f.Parse(test.input)
+			if result != test.expected || ok != test.ok {
+				t.Errorf("Parse(%q) = %v, %v; want %v, %v", test.input, result, ok, test.expected, test.ok)
+			}
+
+			if output := f.Render(result); output != test.output {
+				t.Errorf("Render() = %q; want %q", output, test.output)
+			}
+		})
+	}
+}
+
+func TestNewFormatValidation(t *testing.T) {
+	if _, err := NewFormat("", "", "", nil, nil, `(?P<message>.+)`, "{{.CommitMessage}}"); err == nil {
+		t.Error("expected error for missing name")
+	}
+	if _, err := NewFormat("bad", "", "", nil, nil, `(`, "{{.CommitMessage}}"); err == nil {
+		t.Error("expected error for invalid parse expression")
+	}
+	if _, err := NewFormat("bad", "", "", nil, nil, `(?P<summary>.+)`, "{{.CommitMessage}}"); err == nil {
+		t.Error("expected error for missing message group")
+	}
+	if _, err := NewFormat("bad", "", "", nil, nil, `(?P<message>.+)`, "{{.CommitMessage"); err == nil {
+		t.Error("expected error for invalid render template")
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	f, err := NewFormat("component-test", "", "[<component>] <summary>", nil, nil, `^\[(?P<scope>[^\]]+)\] (?P<message>.+)$`, "[{{.Scope}}] {{.CommitMessage}}")
+	if err != nil {
+		t.Fatalf("NewFormat() error = %v", err)
+	}
+
+	ct, err := RegisterFormat(f)
+	if err != nil {
+		t.Fatalf("RegisterFormat() error = %v", err)
+	}
+	t.Cleanup(func() {
+		delete(TypeIds, ct)
+		delete(commitTypeFormats, ct)
+		delete(customFormats, ct)
+	})
+
+	if parsed, err := ParseType("component-test"); err != nil || parsed != ct {
+		t.Errorf("ParseType() = %v, %v; want %v", parsed, err, ct)
+	}
+	if ct.CommitFormat() != "[<component>] <summary>" {
+		t.Errorf("CommitFormat() = %q", ct.CommitFormat())
+	}
+	if _, err := RegisterFormat(f); err == nil {
+		t.Error("expected error when registering a format twice")
+	}
+	if _, err := RegisterFormat(&Format{Name: "conventional"}); err == nil {
+		t.Error("expected error when shadowing a built-in type")
+	}
+
+	m := ct.ParseMessage("[api] add endpoint")
+	if m.Scope != "api" || ct.Render(m) != "[api] add endpoint" {
+		t.Errorf("unexpected parse/render result: %v", m)
+	}
+}
@@ -0,0 +1,92 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// variationSelector is the unicode variation selector that some emoji are
+// written with (e.g. "♻️"), and which is often omitted by editors and models.
+const variationSelector = "\ufe0f"
+
+var gitmojiShortcodeRegex = regexp.MustCompile(`^:[a-z0-9_+\-]+:$`)
+
+// IsGitmoji reports whether s is a gitmoji, either as a shortcode (e.g.
+// ":sparkles:") or as a known unicode emoji (e.g. "✨").
+func IsGitmoji(s string) bool {
+	if gitmojiShortcodeRegex.MatchString(s) {
+		return true
+	}
+	_, ok := gitmojiShortcodeForEmoji(s)
+	return ok
+}
+
+// IsGitmojiEmoji reports whether s is a known gitmoji written as a unicode
+// emoji rather than as a shortcode.
+func IsGitmojiEmoji(s string) bool {
+	_, ok := gitmojiShortcodeForEmoji(s)
+	return ok
+}
+
+// GitmojiShortcode returns the shortcode for the given gitmoji. Shortcodes
+// are returned unchanged, unicode emoji are looked up in the gitmoji table.
+func GitmojiShortcode(s string) (string, bool) {
+	if gitmojiShortcodeRegex.MatchString(s) {
+		return s, true
+	}
+	return gitmojiShortcodeForEmoji(s)
+}
+
+// GitmojiEmoji returns the unicode emoji for the given gitmoji shortcode.
+func GitmojiEmoji(shortcode string) (string, bool) {
+	emoji, ok := gitmojiEmojis[shortcode]
+	return emoji, ok
+}
+
+func gitmojiShortcodeForEmoji(s string) (string, bool) {
+	s = strings.ReplaceAll(s, variationSelector, "")
+	if s == "" {
+		return "", false
+	}
+	for shortcode, emoji := range gitmojiEmojis {
+		if strings.ReplaceAll(emoji, variationSelector, "") == s {
+			return shortcode, true
+		}
+	}
+	return "", false
+}
+
+// splitGitmojiPrefix splits a leading gitmoji from the message. It returns the
+// gitmoji exactly as written and the remainder of the message.
+func splitGitmojiPrefix(message string) (string, string, bool) {
+	if strings.HasPrefix(message, ":") {
+		if end := strings.Index(message[1:], ":"); end > 0 {
+			shortcode := message[:end+2]
+			if gitmojiShortcodeRegex.MatchString(shortcode) {
+				return shortcode, message[len(shortcode):], true
+			}
+		}
+		return "", message, false
+	}
+
+	// prefer the longest matching emoji, some emoji are prefixes of others
+	var best string
+	for _, emoji := range gitmojiEmojis {
+		for _, candidate := range []string{emoji, strings.ReplaceAll(emoji, variationSelector, "")} {
+			if strings.HasPrefix(message, candidate) && len(candidate) > len(best) {
+				best = candidate
+			}
+		}
+	}
+	if best == "" {
+		return "", message, false
+	}
+
+	rest := message[len(best):]
+	// keep a trailing variation selector with the emoji it belongs to
+	if strings.HasPrefix(rest, variationSelector) {
+		best += variationSelector
+		rest = rest[len(variationSelector):]
+	}
+	return best, rest, true
+}
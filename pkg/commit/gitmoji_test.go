@@ -0,0 +1,38 @@
+package commit
+
+import "testing"
+
+func TestGitmojiTablesAreConsistent(t *testing.T) {
+	for shortcode := range GitmojiTypes {
+		if _, ok := GitmojiEmoji(shortcode); !ok {
+			t.Errorf("gitmoji %s has no unicode emoji", shortcode)
+		}
+	}
+	for shortcode := range gitmojiEmojis {
+		if _, ok := GitmojiTypes[shortcode]; !ok {
+			t.Errorf("gitmoji %s has no description", shortcode)
+		}
+	}
+}
+
+func TestGitmojiShortcode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{input: ":sparkles:", expected: ":sparkles:", ok: true},
+		{input: "✨", expected: ":sparkles:", ok: true},
+		{input: "♻️", expected: ":recycle:", ok: true},
+		{input: "♻", expected: ":recycle:", ok: true},
+		{input: "feat", expected: "", ok: false},
+		{input: "", expected: "", ok: false},
+	}
+
+	for _, test := range tests {
+		result, ok := GitmojiShortcode(test.input)
+		if result != test.expected || ok != test.ok {
+			t.Errorf("GitmojiShortcode(%q) = %q, %v; want %q, %v", test.input, result, ok, test.expected, test.ok)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/duke-git/lancet/v2/strutil"
+)
+
+// Rule names, following the commitlint naming where possible.
+const (
+	RuleHeaderMaxLength = "header-max-length"
+	RuleTypeEmpty       = "type-empty"
+	RuleTypeEnum        = "type-enum"
+	RuleTypeCase        = "type-case"
+	RuleScopeEmpty      = "scope-empty"
+	RuleScopeEnum       = "scope-enum"
+	RuleSubjectEmpty    = "subject-empty"
+	RuleSubjectCase     = "subject-case"
+	RuleSubjectFullStop = "subject-full-stop"
+)
+
+// Case names used by the subject-case rule.
+const (
+	LowerCase    = "lower-case"
+	UpperCase    = "upper-case"
+	SentenceCase = "sentence-case"
+	StartCase    = "start-case"
+	PascalCase   = "pascal-case"
+)
+
+// CaseRule constrains the case of a message part. With Never set, the part
+// must not be in any of the cases, otherwise it must be in one of them.
+type CaseRule struct {
+	Never bool
+	Cases []string
+}
+
+// Rules configures commit message validation, modelled after the commitlint
+// rules of the same name.
+type Rules struct {
+	// HeaderMaxLength is the maximum length of the first line (0 disables).
+	HeaderMaxLength int
+	// TypeRequired requires the header to contain a type.
+	TypeRequired bool
+	// Types is the list of allowed types (empty allows any).
+	Types []string
+	// Scopes is the list of allowed scopes (empty allows any).
+	Scopes []string
+	// ScopeRequired requires the header to contain a scope.
+	ScopeRequired bool
+	// SubjectFullStop is the character the subject must not end with
+	// (empty disables).
+	SubjectFullStop string
+	// SubjectCase constrains the case of the subject.
+	SubjectCase CaseRule
+}
+
+// Violation is a single rule violation found in a commit message.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String returns the violation in the "message [rule]" form used by commitlint.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s [%s]", v.Message, v.Rule)
+}
+
+// DefaultRules returns the default validation rules for the commit type.
+func DefaultRules(t Type) Rules {
+	rules := Rules{
+		HeaderMaxLength: DefaultMaxLength,
+		SubjectFullStop: ".",
+	}
+
+	switch t {
+	case ConventionalType:
+		rules.TypeRequired = true
+		rules.Types = sortedKeys(ConventionalCommitTypes)
+		rules.SubjectCase = CaseRule{
+			Never: true,
+			Cases: []string{SentenceCase, StartCase, PascalCase, UpperCase},
+		}
+	case GitmojiType:
+		rules.TypeRequired = true
+		rules.Types = sortedKeys(GitmojiTypes)
+	default:
+		if f, ok := t.CustomFormat(); ok {
+			rules.TypeRequired = f.HasType()
+			rules.Types = sortedKeys(f.Types)
+			rules.Scopes = sortedKeys(f.Scopes)
+			rules.ScopeRequired = f.HasScope() && len(f.Scopes) > 0
+		}
+	}
+
+	return rules
+}
+
+// Lint validates the commit message against the rules and returns all found
+// violations.
+func (r Rules) Lint(t Type, message string) []Violation {
+	var violations []Violation
+
+	header := Header(message)
+	m, parsed := parseForType(t, header)
+
+	if r.HeaderMaxLength > 0 && utf8.RuneCountInString(header) > r.HeaderMaxLength {
+		violations = append(violations, Violation{
+			Rule:    RuleHeaderMaxLength,
+			Message: fmt.Sprintf("header must not be longer than %d characters, current length is %d", r.HeaderMaxLength, utf8.RuneCountInString(header)),
+		})
+	}
+
+	if r.TypeRequired && (!parsed || m.Type == "") {
+		violations = append(violations, Violation{
+			Rule:    RuleTypeEmpty,
+			Message: fmt.Sprintf("header must be in the format %q", t.CommitFormat()),
+		})
+	}
+
+	if m.Type != "" {
+		if t != GitmojiType && !IsGitmoji(m.Type) && m.Type != strings.ToLower(m.Type) {
+			violations = append(violations, Violation{
+				Rule:    RuleTypeCase,
+				Message: "type must be lower-case",
+			})
+		}
+
+		if len(r.Types) > 0 && !slices.Contains(r.Types, normalizeType(m.Type)) {
+			violations = append(violations, Violation{
+				Rule:    RuleTypeEnum,
+				Message: fmt.Sprintf("type %q must be one of [%s]", m.Type, strings.Join(r.Types, ", ")),
+			})
+		}
+	}
+
+	scope := strings.TrimSpace(m.Scope)
+	if r.ScopeRequired && scope == "" {
+		violations = append(violations, Violation{
+			Rule:    RuleScopeEmpty,
+			Message: "scope may not be empty",
+		})
+	}
+
+	if scope != "" && len(r.Scopes) > 0 {
+		for _, s := range strings.Split(scope, ",") {
+			if s = strings.TrimSpace(s); !slices.Contains(r.Scopes, s) {
+				violations = append(violations, Violation{
+					Rule:    RuleScopeEnum,
+					Message: fmt.Sprintf("scope %q must be one of [%s]", s, strings.Join(r.Scopes, ", ")),
+				})
+			}
+		}
+	}
+
+	subject := strings.TrimSpace(m.CommitMessage)
+	if subject == "" {
+		violations = append(violations, Violation{
+			Rule:    RuleSubjectEmpty,
+			Message: "subject may not be empty",
+		})
+		return violations
+	}
+
+	if r.SubjectFullStop != "" && strings.HasSuffix(subject, r.SubjectFullStop) {
+		violations = append(violations, Violation{
+			Rule:    RuleSubjectFullStop,
+			Message: fmt.Sprintf("subject may not end with full stop %q", r.SubjectFullStop),
+		})
+	}
+
+	if len(r.SubjectCase.Cases) > 0 {
+		matches := slices.ContainsFunc(r.SubjectCase.Cases, func(c string) bool {
+			return isCase(subject, c)
+		})
+		if matches == r.SubjectCase.Never {
+			condition := "must be"
+			if r.SubjectCase.Never {
+				condition = "must not be"
+			}
+			violations = append(violations, Violation{
+				Rule:    RuleSubjectCase,
+				Message: fmt.Sprintf("subject %s %s", condition, strings.Join(r.SubjectCase.Cases, ", ")),
+			})
+		}
+	}
+
+	return violations
+}
+
+// MaxLength returns the maximum header length to generate messages for, which
+// is the default when the header-max-length rule is disabled.
+func (r Rules) MaxLength() int {
+	if r.HeaderMaxLength <= 0 {
+		return DefaultMaxLength
+	}
+	return r.HeaderMaxLength
+}
+
+// Describe returns the rules as short sentences, e.g. to show them next to a
+// message being edited.
+func (r Rules) Describe(t Type) []string {
+	var rules []string
+
+	if format := t.CommitFormat(); format != "" {
+		rules = append(rules, fmt.Sprintf("header in the format %q", format))
+	}
+
+	if r.HeaderMaxLength > 0 {
+		rules = append(rules, fmt.Sprintf("header of at most %d characters", r.HeaderMaxLength))
+	}
+
+	if len(r.Types) > 0 {
+		rules = append(rules, fmt.Sprintf("type one of [%s]", strings.Join(r.Types, ", ")))
+	} else if r.TypeRequired {
+		rules = append(rules, "type required")
+	}
+
+	if len(r.Scopes) > 0 {
+		rules = append(rules, fmt.Sprintf("scope one of [%s]", strings.Join(r.Scopes, ", ")))
+	}
+	if r.ScopeRequired {
+		rules = append(rules, "scope required")
+	}
+
+	if r.SubjectFullStop != "" {
+		rules = append(rules, fmt.Sprintf("subject not ending with %q", r.SubjectFullStop))
+	}
+
+	if len(r.SubjectCase.Cases) > 0 {
+		condition := "subject in"
+		if r.SubjectCase.Never {
+			condition = "subject not in"
+		}
+		rules = append(rules, fmt.Sprintf("%s %s", condition, strings.Join(r.SubjectCase.Cases, ", ")))
+	}
+
+	return rules
+}
+
+// Fix sanitizes the message and repairs the violations that can be fixed
+// without changing the meaning of the message: trailing full stops, type
+// case and subject case.
+func (r Rules) Fix(t Type, message string) string {
+	message = Sanitize(message)
+
+	header, body, _ := strings.Cut(message, "\n")
+
+	m, parsed := parseForType(t, header)
+	if !parsed {
+		if r.SubjectFullStop != "" {
+			header = strings.TrimSpace(strings.TrimSuffix(header, r.SubjectFullStop))
+		}
+		return joinHeaderAndBody(header, body)
+	}
+
+	if !IsGitmoji(m.Type) {
+		m.Type = strings.ToLower(m.Type)
+	}
+
+	if r.SubjectFullStop != "" {
+		m.CommitMessage = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m.CommitMessage), r.SubjectFullStop))
+	}
+
+	if r.SubjectCase.Never && slices.Contains(r.SubjectCase.Cases, SentenceCase) && !StartsWithAcronym(m.CommitMessage) {
+		m.CommitMessage = strutil.LowerFirst(m.CommitMessage)
+	} else if !r.SubjectCase.Never && slices.Equal(r.SubjectCase.Cases, []string{LowerCase}) {
+		m.CommitMessage = strings.ToLower(m.CommitMessage)
+	}
+
+	return joinHeaderAndBody(t.Render(m), body)
+}
+
+var (
+	sanitizeLabelRegex  = regexp.MustCompile(`(?i)^(here is |here's )?(a |the )?(suggested |generated )?(git )?commit( message)?\s*:\s*`)
+	sanitizeBulletRegex = regexp.MustCompile(`^([-*•]|\d+[.)])\s+`)
+)
+
+// Sanitize removes common artifacts of generated commit messages, such as
+// markdown code fences, "Commit message:" labels, list bullets and quotes
+// around the message.
+func Sanitize(message string) string {
+	message = strings.TrimSpace(message)
+
+	// remove markdown code fences
+	if strings.HasPrefix(message, "```") {
+		lines := strings.Split(message, "\n")
+		lines = lines[1:]
+		if len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[len(lines)-1]), "```") {
+			lines = lines[:len(lines)-1]
+		}
+		message = strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+
+	header, body, _ := strings.Cut(message, "\n")
+	header = strings.TrimSpace(header)
+
+	// remove a label, which may be on a line of its own
+	if label := sanitizeLabelRegex.FindString(header); label != "" {
+		header = strings.TrimSpace(header[len(label):])
+		if header == "" {
+			return Sanitize(body)
+		}
+	}
+
+	header = sanitizeBulletRegex.ReplaceAllString(header, "")
+	header = trimMatchingQuotes(header)
+
+	return joinHeaderAndBody(header, body)
+}
+
+var ignoredMessageRegex = regexp.MustCompile(`^((Merge pull request)|(Merge (.*?) into (.*?)|(Merge branch (.*?)))(?:\r?\n)*$)|^(Merge tag (.*?))(?:\r?\n)*$|^(R|r)evert (.*)|^(amend|fixup|squash)! |^Merge remote-tracking branch|^Automatic merge|^Auto-merged (.*?) into (.*)`)
+
+// IsIgnored reports whether the message is one that git writes itself, such
+// as merges, reverts and autosquash commits, and which shouldn't be linted.
+// The same messages are ignored by default by commitlint.
+func IsIgnored(message string) bool {
+	return ignoredMessageRegex.MatchString(Header(message))
+}
+
+// StripComments removes the comment lines and everything below the scissors
+// line from a commit message file, the way git does when committing.
+func StripComments(message, commentChar string) string {
+	if commentChar == "" {
+		commentChar = "#"
+	}
+
+	scissors := commentChar + " ------------------------ >8 ------------------------"
+
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if line == scissors {
+			break
+		}
+		if strings.HasPrefix(line, commentChar) {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Header returns the first line of the commit message.
+func Header(message string) string {
+	header, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(header)
+}
+
+func joinHeaderAndBody(header, body string) string {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return header
+	}
+	return header + "\n\n" + body
+}
+
+func trimMatchingQuotes(s string) string {
+	for _, quote := range []string{`"`, "'", "`", "**"} {
+		if len(s) >= 2*len(quote) && strings.HasPrefix(s, quote) && strings.HasSuffix(s, quote) {
+			return strings.TrimSpace(s[len(quote) : len(s)-len(quote)])
+		}
+	}
+	return s
+}
+
+// parseForType parses the header for the commit type, reporting whether the
+// header matched the type's format.
+func parseForType(t Type, header string) (Message, bool) {
+	if f, ok := t.CustomFormat(); ok {
+		return f.Parse(header)
+	}
+	m := ParseMessage(header)
+	return m, m.Type != ""
+}
+
+// normalizeType returns gitmoji as shortcodes, so that unicode and shortcode
+// forms are validated against the same table.
+func normalizeType(s string) string {
+	if shortcode, ok := GitmojiShortcode(s); ok {
+		return shortcode
+	}
+	return s
+}
+
+func isCase(s, c string) bool {
+	letters := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return r
+		}
+		return -1
+	}, s)
+
+	switch c {
+	case LowerCase:
+		return s == strings.ToLower(s)
+	case UpperCase:
+		return letters != "" && s == strings.ToUpper(s)
+	case SentenceCase:
+		first, _ := utf8.DecodeRuneInString(letters)
+		return unicode.IsUpper(first) && !isCase(s, UpperCase) && !StartsWithAcronym(s)
+	case StartCase:
+		words := strings.Fields(s)
+		for _, w := range words {
+			first, _ := utf8.DecodeRuneInString(w)
+			if !unicode.IsUpper(first) {
+				return false
+			}
+		}
+		return len(words) > 1 && !isCase(s, UpperCase)
+	case PascalCase:
+		first, _ := utf8.DecodeRuneInString(s)
+		return unicode.IsUpper(first) && !strings.ContainsAny(s, " _-") && !isCase(s, UpperCase)
+	default:
+		return false
+	}
+}
+
+// StartsWithAcronym reports whether the first word is written in upper case,
+// e.g. "README" or "API", and shouldn't be lowercased.
+func StartsWithAcronym(s string) bool {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return false
+	}
+	return utf8.RuneCountInString(words[0]) > 1 && isCase(words[0], UpperCase)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,330 @@
+package commit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func violationRules(violations []Violation) []string {
+	var rules []string
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestRulesLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        Type
+		rules    Rules
+		message  string
+		expected []string
+	}{
+		{
+			name:     "valid conventional message",
+			t:        ConventionalType,
+			rules:    DefaultRules(ConventionalType),
+			message:  "feat(parser): add new parsing functions",
+			expected: nil,
+		},
+		{
+			name:     "header too long",
+			t:        ConventionalType,
+			rules:    DefaultRules(ConventionalType),
+			message:  "feat: add a very long description of the change that goes well beyond the limit",
+			expected: []string{RuleHeaderMaxLength},
+		},
+		{
+			name:     "unknown type and trailing period",
+			t:        ConventionalType,
+			rules:    DefaultRules(ConventionalType),
+			message:  "feature: add parser.",
+			expected: []string{RuleTypeEnum, RuleSubjectFullStop},
+		},
+		{
+			name:     "missing type",
+			t:        ConventionalType,
+			rules:    DefaultRules(ConventionalType),
+			message:  "add parser",
+			expected: []string{RuleTypeEmpty},
+		},
+		{
+			name:     "sentence case subject",
+			t:        ConventionalType,
+			rules:    DefaultRules(ConventionalType),
+			message:  "fix: Correct typo",
+			expected: []string{RuleSubjectCase},
+		},
+		{
+			name:     "acronym is not sentence case",
+			t:        ConventionalType,
+			rules:    DefaultRules(ConventionalType),
+			message:  "docs: README updates",
+			expected: nil,
+		},
+		{
+			name:     "upper case type",
+			t:        ConventionalType,
+			rules:    DefaultRules(ConventionalType),
+			message:  "FIX: correct typo",
+			expected: []string{RuleTypeCase, RuleTypeEnum},
+		},
+		{
+			name:     "required scope",
+			t:        ConventionalType,
+			rules:    Rules{ScopeRequired: true, Scopes: []string{"api"}},
+			message:  "fix(ui): correct typo",
+			expected: []string{RuleScopeEnum},
+		},
+		{
+			name:     "missing required scope",
+			t:        ConventionalType,
+			rules:    Rules{ScopeRequired: true},
+			message:  "fix: correct typo",
+			expected: []string{RuleScopeEmpty},
+		},
+		{
+			name:     "gitmoji unicode is validated as shortcode",
+			t:        GitmojiType,
+			rules:    DefaultRules(GitmojiType),
+			message:  "✨ add gitmoji support",
+			expected: nil,
+		},
+		{
+			name:     "simple message",
+			t:        SimpleType,
+			rules:    DefaultRules(SimpleType),
+			message:  "Add parser",
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := violationRules(test.rules.Lint(test.t, test.message))
+			if !slices.Equal(result, test.expected) {
+				t.Errorf("Lint(%q) = %v; want %v", test.message, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestRulesFix(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        Type
+		message  string
+		expected string
+	}{
+		{
+			name:     "label and trailing period",
+			t:        ConventionalType,
+			message:  "Commit message: feat: Add parser.",
+			expected: "feat: add parser",
+		},
+		{
+			name:     "label on its own line",
+			t:        ConventionalType,
+			message:  "Commit message:\nfix(api): handle empty body",
+			expected: "fix(api): handle empty body",
+		},
+		{
+			name:     "quoted message in code fence",
+			t:        ConventionalType,
+			message:  "```\n\"Refactor: simplify loader\"\n```",
+			expected: "refactor: simplify loader",
+		},
+		{
+			name:     "bullet",
+			t:        ConventionalType,
+			message:  "- chore: update dependencies",
+			expected: "chore: update dependencies",
+		},
+		{
+			name:     "keeps body",
+			t:        ConventionalType,
+			message:  "feat: add parser.\n\nIt parses things.",
+			expected: "feat: add parser\n\nIt parses things.",
+		},
+		{
+			name:     "keeps acronym",
+			t:        ConventionalType,
+			message:  "docs: README updates",
+			expected: "docs: README updates",
+		},
+		{
+			name:     "simple message",
+			t:        SimpleType,
+			message:  "'Update the readme.'",
+			expected: "Update the readme",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := DefaultRules(test.t).Fix(test.t, test.message)
+			if result != test.expected {
+				t.Errorf("Fix(%q) = %q; want %q", test.message, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{"Merge branch 'main' into feature", true},
+		{"Merge pull request #12 from user/branch", true},
+		{"Revert \"feat: add parser\"", true},
+		{"fixup! feat: add parser", true},
+		{"feat: add parser", false},
+		{"merge parser options", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := IsIgnored(tt.message); got != tt.expected {
+				t.Errorf("IsIgnored(%q) = %v, want %v", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRulesDescribe(t *testing.T) {
+	rules := DefaultRules(ConventionalType)
+	rules.Types = []string{"feat", "fix"}
+	rules.Scopes = []string{"api"}
+
+	expected := []string{
+		`header in the format "<type>(<optional scope>): <commit message>"`,
+		"header of at most 72 characters",
+		"type one of [feat, fix]",
+		"scope one of [api]",
+		`subject not ending with "."`,
+		"subject not in sentence-case, start-case, pascal-case, upper-case",
+	}
+	if got := rules.Describe(ConventionalType); !slices.Equal(got, expected) {
+		t.Errorf("Describe() = %q, want %q", got, expected)
+	}
+
+	if got := (Rules{}).Describe(SimpleType); len(got) != 1 {
+		t.Errorf("Describe() without rules = %q, want only the format", got)
+	}
+}
+
+func TestRulesMaxLength(t *testing.T) {
+	if got := DefaultRules(ConventionalType).MaxLength(); got != DefaultMaxLength {
+		t.Errorf("MaxLength() = %d; want %d", got, DefaultMaxLength)
+	}
+
+	if got := (Rules{HeaderMaxLength: 100}).MaxLength(); got != 100 {
+		t.Errorf("MaxLength() = %d; want 100", got)
+	}
+
+	// a disabled header-max-length rule doesn't ask for empty headers
+	dir := t.TempDir()
+	config := `{"rules": {"header-max-length": [0, "always", 100]}}`
+	if err := os.WriteFile(filepath.Join(dir, ".commitlintrc.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, _, err := LoadCommitlintRules(dir, DefaultRules(ConventionalType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.HeaderMaxLength != 0 {
+		t.Errorf("HeaderMaxLength = %d; want the rule disabled", rules.HeaderMaxLength)
+	}
+	if got := rules.MaxLength(); got != DefaultMaxLength {
+		t.Errorf("MaxLength() = %d; want %d", got, DefaultMaxLength)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	message := `feat: add parser
+
+Adds the parser.
+# Please enter the commit message for your changes.
+#
+# ------------------------ >8 ------------------------
+diff --git a/parser.go b/parser.go
+`
+
+	expected := "feat: add parser\n\nAdds the parser."
+	if got := StripComments(message, ""); got != expected {
+		t.Errorf("StripComments() = %q, want %q", got, expected)
+	}
+
+	if got := StripComments("fix: typo\n; comment", ";"); got != "fix: typo" {
+		t.Errorf("StripComments() with custom comment char = %q", got)
+	}
+}
+
+func TestLoadCommitlintRules(t *testing.T) {
+	dir := t.TempDir()
+
+	rules, found, err := LoadCommitlintRules(dir, DefaultRules(ConventionalType))
+	if err != nil || found {
+		t.Fatalf("LoadCommitlintRules() found = %v, err = %v; want no config", found, err)
+	}
+	if rules.HeaderMaxLength != DefaultMaxLength {
+		t.Errorf("expected default rules to be returned unchanged")
+	}
+
+	config := `{
+  "extends": ["@commitlint/config-conventional"],
+  "rules": {
+    "header-max-length": [2, "always", 100],
+    "type-enum": [2, "always", ["feat", "fix"]],
+    "scope-empty": [2, "never"],
+    "subject-case": [0],
+    "subject-full-stop": [1, "never", "!"]
+  }
+}`
+	if err := os.WriteFile(filepath.Join(dir, ".commitlintrc.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, found, err = LoadCommitlintRules(dir, DefaultRules(ConventionalType))
+	if err != nil || !found {
+		t.Fatalf("LoadCommitlintRules() found = %v, err = %v", found, err)
+	}
+
+	if rules.HeaderMaxLength != 100 {
+		t.Errorf("HeaderMaxLength = %d; want 100", rules.HeaderMaxLength)
+	}
+	if !slices.Equal(rules.Types, []string{"feat", "fix"}) {
+		t.Errorf("Types = %v", rules.Types)
+	}
+	if !rules.ScopeRequired {
+		t.Errorf("expected scope to be required")
+	}
+	if len(rules.SubjectCase.Cases) != 0 {
+		t.Errorf("expected subject case rule to be disabled")
+	}
+	if rules.SubjectFullStop != "!" {
+		t.Errorf("SubjectFullStop = %q; want %q", rules.SubjectFullStop, "!")
+	}
+}
+
+func TestLoadCommitlintRulesFromPackageJSON(t *testing.T) {
+	dir := t.TempDir()
+
+	pkg := `{"name": "app", "commitlint": {"rules": {"scope-enum": [2, "always", ["api", "ui"]]}}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(pkg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, found, err := LoadCommitlintRules(dir, DefaultRules(ConventionalType))
+	if err != nil || !found {
+		t.Fatalf("LoadCommitlintRules() found = %v, err = %v", found, err)
+	}
+	if !slices.Equal(rules.Scopes, []string{"api", "ui"}) {
+		t.Errorf("Scopes = %v", rules.Scopes)
+	}
+}
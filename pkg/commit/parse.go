@@ -2,14 +2,17 @@ package commit
 
 import "regexp"
 
-var commitMessageRegex = regexp.MustCompile(`^(?P<type>\w+)(\((?P<scope>[^)]+)\))?(!)?: (?P<message>.+)$`)
+var (
+	commitMessageRegex = regexp.MustCompile(`^(?P<type>\w+)(\((?P<scope>[^)]+)\))?(!)?: (?P<message>.+)$`)
+	// gitmojiMessageRegex matches the remainder of a gitmoji commit message
+	// after the leading gitmoji, following "<gitmoji> [(scope)][:] <message>".
+	gitmojiMessageRegex = regexp.MustCompile(`^(\s*\((?P<scope>[^)]+)\))?:?\s+(?P<message>.+)$`)
+)
 
 func ParseMessage(message string) Message {
 	match := commitMessageRegex.FindStringSubmatch(message)
 	if len(match) == 0 {
-		return Message{
-			CommitMessage: message,
-		}
+		return parseGitmojiMessage(message)
 	}
 
 	typeString := match[1]
@@ -30,3 +33,27 @@ func ParseMessage(message string) Message {
 		CommitMessage: messageString,
 	}
 }
+
+// parseGitmojiMessage parses messages starting with a gitmoji, written either
+// as a shortcode (":sparkles: add X") or as unicode ("✨ add X").
+func parseGitmojiMessage(message string) Message {
+	gitmoji, rest, ok := splitGitmojiPrefix(message)
+	if !ok {
+		return Message{
+			CommitMessage: message,
+		}
+	}
+
+	match := gitmojiMessageRegex.FindStringSubmatch(rest)
+	if len(match) == 0 {
+		return Message{
+			CommitMessage: message,
+		}
+	}
+
+	return Message{
+		Type:          gitmoji,
+		Scope:         match[2],
+		CommitMessage: match[3],
+	}
+}
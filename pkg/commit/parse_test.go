@@ -67,6 +67,48 @@ func TestParse(t *testing.T) {
 				CommitMessage: "remove unused imports",
 			},
 		},
+		{
+			input: ":sparkles: add gitmoji support",
+			expected: Message{
+				Type:          ":sparkles:",
+				CommitMessage: "add gitmoji support",
+			},
+		},
+		{
+			input: "✨ add gitmoji support",
+			expected: Message{
+				Type:          "✨",
+				CommitMessage: "add gitmoji support",
+			},
+		},
+		{
+			input: "♻️ simplify parser",
+			expected: Message{
+				Type:          "♻️",
+				CommitMessage: "simplify parser",
+			},
+		},
+		{
+			input: "♻ simplify parser",
+			expected: Message{
+				Type:          "♻",
+				CommitMessage: "simplify parser",
+			},
+		},
+		{
+			input: ":zap: (api): improve performance",
+			expected: Message{
+				Type:          ":zap:",
+				Scope:         "api",
+				CommitMessage: "improve performance",
+			},
+		},
+		{
+			input: ":not-a-gitmoji add thing",
+			expected: Message{
+				CommitMessage: ":not-a-gitmoji add thing",
+			},
+		},
 		{
 			input: "wrong format message",
 			expected: Message{
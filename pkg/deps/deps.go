@@ -0,0 +1,214 @@
+// Package deps detects dependency changes from the diffs of package manifests,
+// to describe dependency updates without a model.
+package deps
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/zbiljic/kai/pkg/commit"
+)
+
+// manifestParsers are the supported manifests by file name, in the order they
+// are checked.
+var manifestParsers = []struct {
+	name  string
+	parse func(content string) map[string]string
+}{
+	{"go.mod", parseGoMod},
+	{"package.json", parsePackageJSON},
+	{"Cargo.toml", parseCargoToml},
+	{"pyproject.toml", parsePyproject},
+}
+
+// lockfiles are the lockfiles of the supported manifests by file name.
+var lockfiles = []string{
+	"go.sum",
+	"go.work.sum",
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"bun.lockb",
+	"bun.lock",
+	"Cargo.lock",
+	"poetry.lock",
+	"uv.lock",
+	"pdm.lock",
+	"Pipfile.lock",
+}
+
+// Change is a change of a dependency in a manifest. From is empty for an added
+// dependency, and To for a removed one.
+type Change struct {
+	Manifest string
+	Name     string
+	From     string
+	To       string
+}
+
+// String describes the change, e.g. "bump x from 1.0.0 to 1.1.0".
+func (c Change) String() string {
+	switch {
+	case c.From == "":
+		return fmt.Sprintf("add %s %s", c.Name, c.To)
+	case c.To == "":
+		return fmt.Sprintf("remove %s", c.Name)
+	default:
+		return fmt.Sprintf("bump %s from %s to %s", c.Name, c.From, c.To)
+	}
+}
+
+// IsManifest reports whether the file is a supported package manifest.
+func IsManifest(file string) bool {
+	return manifestParser(file) != nil
+}
+
+// IsLockfile reports whether the file is a lockfile of a supported package
+// manager.
+func IsLockfile(file string) bool {
+	return slices.Contains(lockfiles, path.Base(file))
+}
+
+func manifestParser(file string) func(string) map[string]string {
+	name := path.Base(file)
+	for _, p := range manifestParsers {
+		if p.name == name {
+			return p.parse
+		}
+	}
+	return nil
+}
+
+// Diff returns the dependency changes between the old and new content of the
+// manifest, sorted by dependency name. It returns nil for unsupported files.
+func Diff(manifest, oldContent, newContent string) []Change {
+	parse := manifestParser(manifest)
+	if parse == nil {
+		return nil
+	}
+
+	oldDeps, newDeps := parse(oldContent), parse(newContent)
+
+	var changes []Change
+	for name, to := range newDeps {
+		if from := oldDeps[name]; from != to {
+			changes = append(changes, Change{Manifest: manifest, Name: name, From: from, To: to})
+		}
+	}
+	for name, from := range oldDeps {
+		if _, ok := newDeps[name]; !ok {
+			changes = append(changes, Change{Manifest: manifest, Name: name, From: from})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return changes
+}
+
+// OnlyDependencies reports whether the manifest change consists only of the
+// dependency changes, i.e. whether the old and new content are the same once
+// the lines mentioning a changed dependency are left out.
+func OnlyDependencies(oldContent, newContent string, changes []Change) bool {
+	strip := func(content string) []string {
+		var lines []string
+	lines:
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			for _, c := range changes {
+				if strings.Contains(strings.ToLower(line), strings.ToLower(c.Name)) {
+					continue lines
+				}
+			}
+			// trailing commas move around when JSON entries are added or removed
+			lines = append(lines, strings.TrimSuffix(line, ","))
+		}
+		return lines
+	}
+
+	return slices.Equal(strip(oldContent), strip(newContent))
+}
+
+// Message returns the commit message for the dependency changes in the format
+// of the commit type, the way dependency bots write them. Without changes, the
+// message describes an update of the lockfiles.
+func Message(t commit.Type, changes []Change, lockfiles []string) string {
+	var subject, body string
+
+	switch len(changes) {
+	case 0:
+		names := make([]string, 0, len(lockfiles))
+		for _, lockfile := range lockfiles {
+			names = append(names, path.Base(lockfile))
+		}
+		slices.Sort(names)
+		subject = "update " + strings.Join(slices.Compact(names), ", ")
+	case 1:
+		subject = changes[0].String()
+	default:
+		subject = fmt.Sprintf("update %d dependencies", len(changes))
+		if all(changes, func(c Change) bool { return c.From != "" && c.To != "" }) {
+			subject = fmt.Sprintf("bump %d dependencies", len(changes))
+		}
+		lines := make([]string, 0, len(changes))
+		for _, c := range changes {
+			lines = append(lines, "- "+c.String())
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	switch t {
+	case commit.SimpleType:
+		subject = strings.ToUpper(subject[:1]) + subject[1:]
+	case commit.GitmojiType:
+		subject = commit.Message{Type: gitmoji(changes), CommitMessage: subject}.ToString()
+	default:
+		// the conventional type and the user-defined formats
+		subject = t.Render(commit.Message{Type: "build", Scope: "deps", CommitMessage: subject})
+	}
+
+	if body != "" {
+		return subject + "\n\n" + body
+	}
+	return subject
+}
+
+// gitmoji returns the gitmoji of the dependency changes: added or removed
+// dependencies when all of them are, upgraded ones otherwise.
+func gitmoji(changes []Change) string {
+	switch {
+	case len(changes) > 0 && all(changes, func(c Change) bool { return c.From == "" }):
+		return ":heavy_plus_sign:"
+	case len(changes) > 0 && all(changes, func(c Change) bool { return c.To == "" }):
+		return ":heavy_minus_sign:"
+	default:
+		return ":arrow_up:"
+	}
+}
+
+func all(changes []Change, f func(Change) bool) bool {
+	for _, c := range changes {
+		if !f(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary describes the dependency changes for the prompt, one per line with
+// the manifest.
+func Summary(changes []Change) string {
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", c, c.Manifest))
+	}
+	return strings.Join(lines, "\n")
+}
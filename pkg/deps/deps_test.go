@@ -0,0 +1,265 @@
+package deps
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zbiljic/kai/pkg/commit"
+)
+
+func TestDiffGoMod(t *testing.T) {
+	oldContent := `module example.com/app
+
+go 1.25
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/tidwall/gjson v1.17.0
+	golang.org/x/sys v0.20.0 // indirect
+)
+`
+	newContent := `module example.com/app
+
+go 1.25
+
+require github.com/spf13/cobra v1.9.1
+
+require (
+	github.com/tidwall/gjson v1.17.0
+	golang.org/x/sys v0.21.0 // indirect
+)
+`
+
+	changes := Diff("go.mod", oldContent, newContent)
+	expected := []Change{
+		{Manifest: "go.mod", Name: "github.com/spf13/cobra", From: "v1.8.0", To: "v1.9.1"},
+		{Manifest: "go.mod", Name: "golang.org/x/sys", From: "v0.20.0", To: "v0.21.0"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+
+	if !OnlyDependencies(oldContent, newContent, changes) {
+		t.Error("Expected a dependency-only change")
+	}
+
+	if OnlyDependencies(oldContent, newContent+"\ntoolchain go1.25.1\n", changes) {
+		t.Error("Expected other manifest changes to be detected")
+	}
+}
+
+func TestDiffPackageJSON(t *testing.T) {
+	oldContent := `{
+  "name": "app",
+  "dependencies": {
+    "left-pad": "^1.0.0",
+    "react": "^18.2.0"
+  },
+  "devDependencies": {
+    "typescript": "^5.3.0"
+  }
+}`
+	newContent := `{
+  "name": "app",
+  "dependencies": {
+    "react": "^18.3.1"
+  },
+  "devDependencies": {
+    "typescript": "^5.3.0",
+    "vitest": "^1.6.0"
+  }
+}`
+
+	changes := Diff("web/package.json", oldContent, newContent)
+	expected := []Change{
+		{Manifest: "web/package.json", Name: "left-pad", From: "^1.0.0"},
+		{Manifest: "web/package.json", Name: "react", From: "^18.2.0", To: "^18.3.1"},
+		{Manifest: "web/package.json", Name: "vitest", To: "^1.6.0"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+
+	if !OnlyDependencies(oldContent, newContent, changes) {
+		t.Error("Expected a dependency-only change")
+	}
+}
+
+func TestDiffCargoToml(t *testing.T) {
+	oldContent := `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0.190", features = ["derive"] }
+anyhow = "1.0.75" # errors
+
+[dependencies.tokio]
+version = "1.33.0"
+features = ["full"]
+`
+	newContent := `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0.195", features = ["derive"] }
+anyhow = "1.0.75" # errors
+
+[dependencies.tokio]
+version = "1.35.1"
+features = ["full"]
+`
+
+	changes := Diff("Cargo.toml", oldContent, newContent)
+	expected := []Change{
+		{Manifest: "Cargo.toml", Name: "serde", From: "1.0.190", To: "1.0.195"},
+		{Manifest: "Cargo.toml", Name: "tokio", From: "1.33.0", To: "1.35.1"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+}
+
+func TestDiffPyproject(t *testing.T) {
+	oldContent := `[project]
+name = "app"
+dependencies = [
+    "requests>=2.31.0",
+    "Click[extra]==8.1.7; python_version >= '3.8'",
+]
+
+[tool.poetry.dependencies]
+python = "^3.11"
+httpx = "^0.26.0"
+`
+	newContent := `[project]
+name = "app"
+dependencies = [
+    "requests>=2.32.0",
+    "Click[extra]==8.1.7; python_version >= '3.8'",
+]
+
+[tool.poetry.dependencies]
+python = "^3.12"
+httpx = { version = "^0.27.0", optional = true }
+`
+
+	changes := Diff("pyproject.toml", oldContent, newContent)
+	expected := []Change{
+		{Manifest: "pyproject.toml", Name: "httpx", From: "^0.26.0", To: "^0.27.0"},
+		{Manifest: "pyproject.toml", Name: "requests", From: ">=2.31.0", To: ">=2.32.0"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+}
+
+func TestDiffUnsupported(t *testing.T) {
+	if changes := Diff("requirements.txt", "a==1", "a==2"); changes != nil {
+		t.Errorf("Expected no changes for an unsupported file, got %v", changes)
+	}
+}
+
+func TestIsLockfile(t *testing.T) {
+	for _, file := range []string{"go.sum", "web/package-lock.json", "Cargo.lock", "poetry.lock"} {
+		if !IsLockfile(file) {
+			t.Errorf("Expected %s to be a lockfile", file)
+		}
+	}
+	for _, file := range []string{"go.mod", "package.json", "main.lock.go"} {
+		if IsLockfile(file) {
+			t.Errorf("Expected %s not to be a lockfile", file)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	bump := Change{Manifest: "go.mod", Name: "github.com/spf13/cobra", From: "v1.8.0", To: "v1.9.1"}
+	added := Change{Manifest: "go.mod", Name: "github.com/tidwall/gjson", To: "v1.17.0"}
+	removed := Change{Manifest: "go.mod", Name: "github.com/pkg/errors", From: "v0.9.1"}
+
+	format, err := commit.NewFormat("deps-test", "", "[<component>] <summary>", nil, nil, `^\[(?P<scope>[^\]]+)\] (?P<message>.+)$`, "[{{.Scope}}] {{.CommitMessage}}")
+	if err != nil {
+		t.Fatalf("NewFormat() error = %v", err)
+	}
+	custom, err := commit.RegisterFormat(format)
+	if err != nil {
+		t.Fatalf("RegisterFormat() error = %v", err)
+	}
+	t.Cleanup(func() { delete(commit.TypeIds, custom) })
+
+	tests := []struct {
+		name      string
+		t         commit.Type
+		changes   []Change
+		lockfiles []string
+		expected  string
+	}{
+		{
+			name:     "conventional bump",
+			t:        commit.ConventionalType,
+			changes:  []Change{bump},
+			expected: "build(deps): bump github.com/spf13/cobra from v1.8.0 to v1.9.1",
+		},
+		{
+			name:     "gitmoji bump",
+			t:        commit.GitmojiType,
+			changes:  []Change{bump},
+			expected: ":arrow_up: bump github.com/spf13/cobra from v1.8.0 to v1.9.1",
+		},
+		{
+			name:     "gitmoji add",
+			t:        commit.GitmojiType,
+			changes:  []Change{added},
+			expected: ":heavy_plus_sign: add github.com/tidwall/gjson v1.17.0",
+		},
+		{
+			name:     "gitmoji remove",
+			t:        commit.GitmojiType,
+			changes:  []Change{removed},
+			expected: ":heavy_minus_sign: remove github.com/pkg/errors",
+		},
+		{
+			name:     "gitmoji mixed changes",
+			t:        commit.GitmojiType,
+			changes:  []Change{added, removed},
+			expected: ":arrow_up: update 2 dependencies\n\n- add github.com/tidwall/gjson v1.17.0\n- remove github.com/pkg/errors",
+		},
+		{
+			name:     "user-defined format",
+			t:        custom,
+			changes:  []Change{bump},
+			expected: "[deps] bump github.com/spf13/cobra from v1.8.0 to v1.9.1",
+		},
+		{
+			name:     "simple bump",
+			t:        commit.SimpleType,
+			changes:  []Change{bump},
+			expected: "Bump github.com/spf13/cobra from v1.8.0 to v1.9.1",
+		},
+		{
+			name:    "multiple changes",
+			t:       commit.ConventionalType,
+			changes: []Change{bump, added},
+			expected: "build(deps): update 2 dependencies\n\n" +
+				"- bump github.com/spf13/cobra from v1.8.0 to v1.9.1\n" +
+				"- add github.com/tidwall/gjson v1.17.0",
+		},
+		{
+			name:      "lockfiles only",
+			t:         commit.ConventionalType,
+			lockfiles: []string{"web/package-lock.json", "go.sum"},
+			expected:  "build(deps): update go.sum, package-lock.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Message(tt.t, tt.changes, tt.lockfiles); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
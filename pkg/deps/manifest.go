@@ -0,0 +1,272 @@
+package deps
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseGoMod returns the required modules of a go.mod file with their
+// versions.
+func parseGoMod(content string) map[string]string {
+	deps := make(map[string]string)
+	inRequire := false
+
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "require (":
+			inRequire = true
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inRequire:
+			continue
+		}
+
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			deps[fields[0]] = fields[1]
+		}
+	}
+
+	return deps
+}
+
+// parsePackageJSON returns the dependencies of a package.json file, of all
+// kinds, with their version ranges.
+func parsePackageJSON(content string) map[string]string {
+	var manifest struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+
+	deps := make(map[string]string)
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return deps
+	}
+
+	for _, group := range []map[string]string{
+		manifest.PeerDependencies,
+		manifest.OptionalDependencies,
+		manifest.DevDependencies,
+		manifest.Dependencies,
+	} {
+		for name, version := range group {
+			deps[name] = version
+		}
+	}
+
+	return deps
+}
+
+// parseCargoToml returns the dependencies of a Cargo.toml file, of all kinds,
+// with their versions.
+func parseCargoToml(content string) map[string]string {
+	deps := make(map[string]string)
+
+	for table, entries := range tomlTables(content) {
+		if isCargoDependencyTable(table) {
+			for name, value := range entries {
+				if version := tomlVersion(value); version != "" {
+					deps[name] = version
+				}
+			}
+			continue
+		}
+
+		// dependencies written as tables, e.g. [dependencies.serde]
+		if i := strings.LastIndex(table, "."); i > 0 && isCargoDependencyTable(table[:i]) {
+			if version := tomlVersion(entries["version"]); version != "" {
+				deps[table[i+1:]] = version
+			}
+		}
+	}
+
+	return deps
+}
+
+func isCargoDependencyTable(table string) bool {
+	switch table {
+	case "dependencies", "dev-dependencies", "build-dependencies", "workspace.dependencies":
+		return true
+	}
+	// platform specific dependencies, e.g. [target.'cfg(unix)'.dependencies]
+	return strings.HasPrefix(table, "target.") &&
+		(strings.HasSuffix(table, ".dependencies") ||
+			strings.HasSuffix(table, ".dev-dependencies") ||
+			strings.HasSuffix(table, ".build-dependencies"))
+}
+
+var pep508NameRegex = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+// parsePyproject returns the dependencies of a pyproject.toml file, from the
+// PEP 621 project table and from Poetry, with their version specifiers.
+func parsePyproject(content string) map[string]string {
+	deps := make(map[string]string)
+	tables := tomlTables(content)
+
+	addRequirements := func(value string) {
+		for _, requirement := range tomlStrings(value) {
+			match := pep508NameRegex.FindStringSubmatch(requirement)
+			if match == nil {
+				continue
+			}
+			spec, _, _ := strings.Cut(match[3], ";")
+			deps[strings.ToLower(match[1])] = strings.TrimSpace(spec)
+		}
+	}
+
+	addRequirements(tables["project"]["dependencies"])
+	for _, value := range tables["project.optional-dependencies"] {
+		addRequirements(value)
+	}
+	for _, value := range tables["dependency-groups"] {
+		addRequirements(value)
+	}
+
+	for table, entries := range tables {
+		if table != "tool.poetry.dependencies" && table != "tool.poetry.dev-dependencies" &&
+			!(strings.HasPrefix(table, "tool.poetry.group.") && strings.HasSuffix(table, ".dependencies")) {
+			continue
+		}
+		for name, value := range entries {
+			if name == "python" {
+				continue
+			}
+			if version := tomlVersion(value); version != "" {
+				deps[strings.ToLower(name)] = version
+			}
+		}
+	}
+
+	return deps
+}
+
+// tomlTables returns the key/value pairs of a TOML document by table, with
+// the values as written and multi-line arrays joined. Only the subset of TOML
+// commonly used in manifests is supported.
+func tomlTables(content string) map[string]map[string]string {
+	tables := map[string]map[string]string{"": {}}
+	table := ""
+
+	var (
+		pendingKey   string
+		pendingValue strings.Builder
+		depth        int
+	)
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(tomlStripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if pendingKey != "" {
+			pendingValue.WriteString(" " + line)
+			depth += strings.Count(line, "[") - strings.Count(line, "]")
+			if depth <= 0 {
+				tables[table][pendingKey] = pendingValue.String()
+				pendingKey = ""
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && !strings.Contains(line, "=") {
+			table = tomlKey(strings.Trim(line, "[]"))
+			if _, ok := tables[table]; !ok {
+				tables[table] = make(map[string]string)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = tomlKey(key), strings.TrimSpace(value)
+
+		if depth = strings.Count(value, "[") - strings.Count(value, "]"); depth > 0 {
+			pendingKey = key
+			pendingValue.Reset()
+			pendingValue.WriteString(value)
+			continue
+		}
+
+		tables[table][key] = value
+	}
+
+	return tables
+}
+
+// tomlKey normalizes a (dotted) key, removing the quotes and whitespace.
+func tomlKey(key string) string {
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlStripComment removes a comment from the line, outside of strings.
+func tomlStripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+var (
+	tomlStringRegex  = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"|'([^']*)'`)
+	tomlVersionRegex = regexp.MustCompile(`\bversion\s*=\s*("(?:[^"\\]|\\.)*"|'[^']*')`)
+)
+
+// tomlStrings returns the strings of a TOML value, e.g. of an array.
+func tomlStrings(value string) []string {
+	var values []string
+	for _, match := range tomlStringRegex.FindAllStringSubmatch(value, -1) {
+		if match[2] != "" {
+			values = append(values, match[2])
+			continue
+		}
+		if unquoted, err := strconv.Unquote(`"` + match[1] + `"`); err == nil {
+			values = append(values, unquoted)
+		}
+	}
+	return values
+}
+
+// tomlVersion returns the version of a dependency, written either as a string
+// or as an inline table with a version key.
+func tomlVersion(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		match := tomlVersionRegex.FindStringSubmatch(value)
+		if match == nil {
+			return ""
+		}
+		value = match[1]
+	}
+
+	if values := tomlStrings(value); len(values) == 1 {
+		return values[0]
+	}
+	return ""
+}
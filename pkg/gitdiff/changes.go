@@ -0,0 +1,212 @@
+package gitdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FileChange is the change of a file as described by the headers of a git
+// diff: its status like 'git diff --name-status', mode changes, whether it is
+// binary, and the number of added and deleted lines.
+type FileChange struct {
+	Path       string `json:"path"`
+	OldPath    string `json:"old_path,omitempty"`   // only for renames and copies
+	Status     string `json:"status"`               // A, M, D, R, C or T, as with --name-status
+	Similarity int    `json:"similarity,omitempty"` // in percent, only for renames and copies
+	OldMode    string `json:"old_mode,omitempty"`
+	NewMode    string `json:"new_mode,omitempty"`
+	OldBlob    string `json:"old_blob,omitempty"` // abbreviated hashes from the index line
+	NewBlob    string `json:"new_blob,omitempty"`
+	Binary     bool   `json:"binary,omitempty"`
+	OldSize    int64  `json:"old_size,omitempty"` // sizes of binary files in bytes, when known
+	NewSize    int64  `json:"new_size,omitempty"`
+	Added      int    `json:"added"`
+	Deleted    int    `json:"deleted"`
+}
+
+// ModeChanged reports whether the file mode changed, e.g. it became
+// executable.
+func (c FileChange) ModeChanged() bool {
+	return c.OldMode != "" && c.NewMode != "" && c.OldMode != c.NewMode
+}
+
+// String describes the change on a line, e.g. "R95 old.go → new.go +2 −1".
+func (c FileChange) String() string {
+	status := c.Status
+	if c.Similarity > 0 {
+		status += strconv.Itoa(c.Similarity)
+	}
+
+	parts := []string{status}
+	if c.OldPath != "" {
+		parts = append(parts, c.OldPath+" → "+c.Path)
+	} else {
+		parts = append(parts, c.Path)
+	}
+
+	if c.ModeChanged() {
+		parts = append(parts, fmt.Sprintf("mode %s → %s", c.OldMode, c.NewMode))
+	}
+
+	switch {
+	case c.Binary:
+		parts = append(parts, c.binarySize())
+	default:
+		if c.Added > 0 {
+			parts = append(parts, fmt.Sprintf("+%d", c.Added))
+		}
+		if c.Deleted > 0 {
+			parts = append(parts, fmt.Sprintf("−%d", c.Deleted))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (c FileChange) binarySize() string {
+	switch {
+	case c.Status == "A" && c.NewSize > 0:
+		return "binary " + formatSize(c.NewSize)
+	case c.Status == "D" && c.OldSize > 0:
+		return "binary " + formatSize(c.OldSize)
+	case c.OldSize > 0 && c.NewSize > 0:
+		delta := formatSize(c.NewSize - c.OldSize)
+		if c.NewSize >= c.OldSize {
+			delta = "+" + delta
+		}
+		return fmt.Sprintf("binary %s → %s (%s)", formatSize(c.OldSize), formatSize(c.NewSize), delta)
+	default:
+		return "binary"
+	}
+}
+
+func formatSize(size int64) string {
+	sign := ""
+	if size < 0 {
+		sign, size = "−", -size
+	}
+
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%s%d B", sign, size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%s%.1f KiB", sign, float64(size)/1024)
+	default:
+		return fmt.Sprintf("%s%.1f MiB", sign, float64(size)/(1024*1024))
+	}
+}
+
+// ParseFileChanges returns the changes of the files of a git diff, in the
+// order of the diff.
+func ParseFileChanges(diffOutput string) []*FileChange {
+	var (
+		changes []*FileChange
+		current *FileChange
+		inHunk  bool
+	)
+
+	for _, line := range strings.Split(diffOutput, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			current = &FileChange{Status: "M"}
+			if fields := strings.Fields(line); len(fields) == 4 {
+				current.Path = stripGitDiffPrefix(fields[3])
+			}
+			changes = append(changes, current)
+			inHunk = false
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if inHunk {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				current.Added++
+			case strings.HasPrefix(line, "-"):
+				current.Deleted++
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "new file mode "):
+			current.Status = "A"
+			current.NewMode = strings.TrimPrefix(line, "new file mode ")
+		case strings.HasPrefix(line, "deleted file mode "):
+			current.Status = "D"
+			current.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+		case strings.HasPrefix(line, "old mode "):
+			current.OldMode = strings.TrimPrefix(line, "old mode ")
+		case strings.HasPrefix(line, "new mode "):
+			current.NewMode = strings.TrimPrefix(line, "new mode ")
+		case strings.HasPrefix(line, "similarity index "):
+			current.Similarity, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"))
+		case strings.HasPrefix(line, "rename from "), strings.HasPrefix(line, "copy from "):
+			current.Status = "R"
+			if strings.HasPrefix(line, "copy ") {
+				current.Status = "C"
+			}
+			current.OldPath = strings.SplitN(line, " from ", 2)[1]
+		case strings.HasPrefix(line, "rename to "):
+			current.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "copy to "):
+			current.Path = strings.TrimPrefix(line, "copy to ")
+		case strings.HasPrefix(line, "index "):
+			fields := strings.Fields(strings.TrimPrefix(line, "index "))
+			if len(fields) > 0 {
+				current.OldBlob, current.NewBlob, _ = strings.Cut(fields[0], "..")
+			}
+			if len(fields) > 1 && current.OldMode == "" && current.NewMode == "" {
+				current.OldMode, current.NewMode = fields[1], fields[1]
+			}
+		case strings.HasPrefix(line, "+++ ") && !strings.HasSuffix(line, "/dev/null"):
+			current.Path = stripGitDiffPrefix(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "--- ") && current.Status == "D":
+			current.Path = stripGitDiffPrefix(strings.TrimPrefix(line, "--- "))
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			current.Binary = true
+		}
+	}
+
+	for _, c := range changes {
+		// a change between a file and a symlink or a submodule
+		if c.ModeChanged() && (isSpecialMode(c.OldMode) || isSpecialMode(c.NewMode)) {
+			c.Status = "T"
+		}
+	}
+
+	return changes
+}
+
+func isSpecialMode(mode string) bool {
+	return mode == "120000" || mode == "160000"
+}
+
+// FormatChangeSummary returns the summary of the file changes, one per line
+// like 'git diff --name-status' with the mode changes, binary sizes and the
+// added and deleted lines, followed by the totals like 'git diff --shortstat'.
+func FormatChangeSummary(changes []*FileChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var (
+		sb             strings.Builder
+		added, deleted int
+	)
+	for _, c := range changes {
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+		added += c.Added
+		deleted += c.Deleted
+	}
+
+	fmt.Fprintf(&sb, "%d file(s) changed, %d insertion(s)(+), %d deletion(s)(−)", len(changes), added, deleted)
+
+	return sb.String()
+}
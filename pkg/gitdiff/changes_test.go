@@ -0,0 +1,125 @@
+package gitdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+const fileChangesDiff = `diff --git a/main.go b/main.go
+index 1234567..abcdefg 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+-const name = "old"
++const name = "new"
++const debug = true
+diff --git a/old/util.go b/new/util.go
+similarity index 95%
+rename from old/util.go
+rename to new/util.go
+index 1111111..2222222 100644
+--- a/old/util.go
++++ b/new/util.go
+@@ -1 +1 @@
+-package old
++package new
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git a/logo.png b/logo.png
+index 3333333..4444444 100644
+Binary files a/logo.png and b/logo.png differ
+diff --git a/removed.txt b/removed.txt
+deleted file mode 100644
+index 5555555..0000000
+--- a/removed.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-one
+-two
+diff --git a/added.txt b/added.txt
+new file mode 100644
+index 0000000..6666666
+--- /dev/null
++++ b/added.txt
+@@ -0,0 +1 @@
++one
+`
+
+func TestParseFileChanges(t *testing.T) {
+	changes := ParseFileChanges(fileChangesDiff)
+	if len(changes) != 6 {
+		t.Fatalf("Expected 6 file changes, got %d", len(changes))
+	}
+
+	expected := []string{
+		"M main.go +2 −1",
+		"R95 old/util.go → new/util.go +1 −1",
+		"M run.sh mode 100644 → 100755",
+		"M logo.png binary",
+		"D removed.txt −2",
+		"A added.txt +1",
+	}
+	for i, c := range changes {
+		if got := c.String(); got != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], got)
+		}
+	}
+
+	if changes[3].OldBlob != "3333333" || changes[3].NewBlob != "4444444" {
+		t.Errorf("Expected the blobs of the binary file, got %q and %q", changes[3].OldBlob, changes[3].NewBlob)
+	}
+
+	changes[3].OldSize, changes[3].NewSize = 2048, 3584
+	if got := changes[3].String(); got != "M logo.png binary 2.0 KiB → 3.5 KiB (+1.5 KiB)" {
+		t.Errorf("Unexpected binary change description: %q", got)
+	}
+
+	summary := FormatChangeSummary(changes)
+	if !strings.HasSuffix(summary, "6 file(s) changed, 4 insertion(s)(+), 4 deletion(s)(−)") {
+		t.Errorf("Unexpected summary totals:\n%s", summary)
+	}
+}
+
+func TestParseDiffFileChange(t *testing.T) {
+	hunks, err := ParseDiff(fileChangesDiff)
+	if err != nil {
+		t.Fatalf("ParseDiff returned error: %v", err)
+	}
+
+	for _, hunk := range hunks {
+		if hunk.File == nil {
+			t.Fatalf("Expected hunk %s to have its file change", hunk.ID)
+		}
+		if hunk.File.Path != hunk.FilePath {
+			t.Errorf("Expected file change of %s, got %s", hunk.FilePath, hunk.File.Path)
+		}
+	}
+
+	if hunks[1].File.Status != "R" || hunks[1].File.OldPath != "old/util.go" {
+		t.Errorf("Expected the rename to survive parsing, got %+v", hunks[1].File)
+	}
+}
+
+func TestParseDiffFiles(t *testing.T) {
+	hunks, files, err := ParseDiffFiles(fileChangesDiff)
+	if err != nil {
+		t.Fatalf("ParseDiffFiles returned error: %v", err)
+	}
+
+	if len(hunks) != 4 {
+		t.Errorf("Expected 4 hunks, got %d", len(hunks))
+	}
+
+	// the mode change and the binary file have no hunks
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files without hunks, got %d", len(files))
+	}
+	if got := files[0].String(); got != "M run.sh mode 100644 → 100755" {
+		t.Errorf("Unexpected mode change: %q", got)
+	}
+	if !files[1].Binary || files[1].Path != "logo.png" {
+		t.Errorf("Expected the binary file, got %+v", files[1])
+	}
+}
@@ -0,0 +1,148 @@
+package gitdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict represents a conflict region of a file, between the conflict
+// markers written by git.
+type Conflict struct {
+	StartLine   int      `json:"start_line"` // index of the "<<<<<<<" line
+	EndLine     int      `json:"end_line"`   // index of the ">>>>>>>" line
+	OursLabel   string   `json:"ours_label"`
+	BaseLabel   string   `json:"base_label"`
+	TheirsLabel string   `json:"theirs_label"`
+	Ours        []string `json:"ours"`
+	Base        []string `json:"base"` // only with the diff3 or zdiff3 conflict style
+	Theirs      []string `json:"theirs"`
+	HasBase     bool     `json:"has_base"`
+}
+
+// String returns the conflict region as written in the file.
+func (c Conflict) String() string {
+	lines := []string{conflictMarkerLine(conflictOursMarker, c.OursLabel)}
+	lines = append(lines, c.Ours...)
+	if c.HasBase {
+		lines = append(lines, conflictMarkerLine(conflictBaseMarker, c.BaseLabel))
+		lines = append(lines, c.Base...)
+	}
+	lines = append(lines, conflictSeparatorMarker)
+	lines = append(lines, c.Theirs...)
+	lines = append(lines, conflictMarkerLine(conflictTheirsMarker, c.TheirsLabel))
+	return strings.Join(lines, "\n")
+}
+
+const (
+	conflictOursMarker      = "<<<<<<<"
+	conflictBaseMarker      = "|||||||"
+	conflictSeparatorMarker = "======="
+	conflictTheirsMarker    = ">>>>>>>"
+)
+
+func conflictMarkerLine(marker, label string) string {
+	if label == "" {
+		return marker
+	}
+	return marker + " " + label
+}
+
+// parseConflictMarker reports whether the line is the given conflict marker,
+// and returns the label following it.
+func parseConflictMarker(line, marker string) (string, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == marker {
+		return "", true
+	}
+	if label, ok := strings.CutPrefix(line, marker+" "); ok {
+		return label, true
+	}
+	return "", false
+}
+
+// ParseConflicts parses the conflict regions of a file's content, including
+// the base sections written with the diff3 conflict style.
+func ParseConflicts(content string) ([]Conflict, error) {
+	const (
+		stateOutside = iota
+		stateOurs
+		stateBase
+		stateTheirs
+	)
+
+	var (
+		conflicts []Conflict
+		current   Conflict
+		state     = stateOutside
+	)
+
+	for i, line := range strings.Split(content, "\n") {
+		switch state {
+		case stateOutside:
+			if label, ok := parseConflictMarker(line, conflictOursMarker); ok {
+				current = Conflict{StartLine: i, OursLabel: label}
+				state = stateOurs
+			}
+		case stateOurs, stateBase:
+			if label, ok := parseConflictMarker(line, conflictBaseMarker); ok && state == stateOurs {
+				current.BaseLabel = label
+				current.HasBase = true
+				state = stateBase
+				continue
+			}
+			if strings.TrimSuffix(line, "\r") == conflictSeparatorMarker {
+				state = stateTheirs
+				continue
+			}
+			if state == stateOurs {
+				current.Ours = append(current.Ours, line)
+			} else {
+				current.Base = append(current.Base, line)
+			}
+		case stateTheirs:
+			if label, ok := parseConflictMarker(line, conflictTheirsMarker); ok {
+				current.TheirsLabel = label
+				current.EndLine = i
+				conflicts = append(conflicts, current)
+				state = stateOutside
+				continue
+			}
+			current.Theirs = append(current.Theirs, line)
+		}
+	}
+
+	if state != stateOutside {
+		return nil, fmt.Errorf("unterminated conflict starting at line %d", current.StartLine+1)
+	}
+
+	return conflicts, nil
+}
+
+// ResolveConflicts replaces the conflict regions with their resolutions,
+// keyed by the index of the conflict. Conflicts without a resolution are left
+// as they are.
+func ResolveConflicts(content string, conflicts []Conflict, resolutions map[int]string) string {
+	lines := strings.Split(content, "\n")
+
+	var (
+		result []string
+		next   int
+	)
+
+	for i, conflict := range conflicts {
+		resolution, ok := resolutions[i]
+		if !ok {
+			continue
+		}
+
+		result = append(result, lines[next:conflict.StartLine]...)
+		if resolution != "" {
+			result = append(result, strings.Split(strings.TrimSuffix(resolution, "\n"), "\n")...)
+		}
+		next = conflict.EndLine + 1
+	}
+
+	result = append(result, lines[next:]...)
+
+	return strings.Join(result, "\n")
+}
@@ -0,0 +1,88 @@
+package gitdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConflicts(t *testing.T) {
+	content := `package main
+
+<<<<<<< HEAD
+const name = "ours"
+||||||| base
+const name = "base"
+=======
+const name = "theirs"
+>>>>>>> feature
+func main() {
+<<<<<<< HEAD
+	run()
+=======
+	run(name)
+	exit()
+>>>>>>> feature
+}
+`
+
+	conflicts, err := ParseConflicts(content)
+	if err != nil {
+		t.Fatalf("Failed to parse conflicts: %s", err)
+	}
+
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %d", len(conflicts))
+	}
+
+	first := conflicts[0]
+	if first.StartLine != 2 || first.EndLine != 8 {
+		t.Errorf("Expected first conflict on lines 2-8, got %d-%d", first.StartLine, first.EndLine)
+	}
+	if !first.HasBase || first.BaseLabel != "base" || len(first.Base) != 1 {
+		t.Errorf("Expected diff3 base section, got %+v", first)
+	}
+	if first.OursLabel != "HEAD" || first.TheirsLabel != "feature" {
+		t.Errorf("Unexpected labels %q and %q", first.OursLabel, first.TheirsLabel)
+	}
+
+	second := conflicts[1]
+	if second.HasBase {
+		t.Errorf("Expected no base section in second conflict")
+	}
+	if len(second.Ours) != 1 || len(second.Theirs) != 2 {
+		t.Errorf("Expected 1 ours and 2 theirs lines, got %d and %d", len(second.Ours), len(second.Theirs))
+	}
+
+	lines := strings.Split(content, "\n")
+	if got := strings.Join(lines[second.StartLine:second.EndLine+1], "\n"); got != second.String() {
+		t.Errorf("String() = %q; want %q", second.String(), got)
+	}
+}
+
+func TestParseConflictsUnterminated(t *testing.T) {
+	_, err := ParseConflicts("<<<<<<< HEAD\nours\n=======\ntheirs\n")
+	if err == nil {
+		t.Error("Expected error for unterminated conflict")
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	content := "a\n<<<<<<< HEAD\nb\n=======\nc\n>>>>>>> x\nd\n<<<<<<< HEAD\ne\n=======\nf\n>>>>>>> x\ng\n"
+
+	conflicts, err := ParseConflicts(content)
+	if err != nil {
+		t.Fatalf("Failed to parse conflicts: %s", err)
+	}
+
+	resolved := ResolveConflicts(content, conflicts, map[int]string{0: "b\nc\n"})
+	expected := "a\nb\nc\nd\n<<<<<<< HEAD\ne\n=======\nf\n>>>>>>> x\ng\n"
+	if resolved != expected {
+		t.Errorf("ResolveConflicts() = %q; want %q", resolved, expected)
+	}
+
+	resolved = ResolveConflicts(content, conflicts, map[int]string{0: "", 1: "f"})
+	expected = "a\nd\nf\ng\n"
+	if resolved != expected {
+		t.Errorf("ResolveConflicts() = %q; want %q", resolved, expected)
+	}
+}
@@ -19,20 +19,36 @@ type Hunk struct {
 	EndLine      int             `json:"end_line"`
 	Content      string          `json:"content"`
 	Context      string          `json:"context"`
-	Dependencies map[string]bool `json:"dependencies"` // Hunk IDs this depends on
-	Dependents   map[string]bool `json:"dependents"`   // Hunk IDs that depend on this
-	ChangeType   string          `json:"change_type"`  // addition, deletion, modification
-	IsNewFile    bool            `json:"is_new_file"`  // true if the file is newly added
+	Dependencies map[string]bool `json:"dependencies"`   // Hunk IDs this depends on
+	Dependents   map[string]bool `json:"dependents"`     // Hunk IDs that depend on this
+	ChangeType   string          `json:"change_type"`    // addition, deletion, modification
+	IsNewFile    bool            `json:"is_new_file"`    // true if the file is newly added
+	File         *FileChange     `json:"file,omitempty"` // the change of the whole file
 }
 
 // ParseDiff parses git diff output into individual hunks.
 func ParseDiff(diffOutput string) ([]*Hunk, error) {
+	hunks, _, err := ParseDiffFiles(diffOutput)
+	return hunks, err
+}
+
+// ParseDiffFiles parses git diff output into individual hunks, and returns
+// the changes of the files without hunks alongside, e.g. pure renames, mode
+// changes and binary files, which are only described by their headers.
+func ParseDiffFiles(diffOutput string) ([]*Hunk, []*FileChange, error) {
 	contextLines := 3
 
 	hunks := []*Hunk{}
 	currentFile := ""
 	isNewFile := false
 
+	// renames, mode changes and binary files are only described by the headers
+	changes := ParseFileChanges(diffOutput)
+	fileChanges := make(map[string]*FileChange, len(changes))
+	for _, change := range changes {
+		fileChanges[change.Path] = change
+	}
+
 	lines := strings.Split(diffOutput, "\n")
 	i := 0
 
@@ -74,14 +90,14 @@ func ParseDiff(diffOutput string) ([]*Hunk, error) {
 			if len(matches) >= 5 {
 				newStart, err := strconv.Atoi(matches[3])
 				if err != nil {
-					return nil, fmt.Errorf("failed to parse hunk start line: %w", err)
+					return nil, nil, fmt.Errorf("failed to parse hunk start line: %w", err)
 				}
 
 				newCount := 1
 				if matches[4] != "" {
 					newCount, err = strconv.Atoi(matches[4])
 					if err != nil {
-						return nil, fmt.Errorf("failed to parse hunk count: %w", err)
+						return nil, nil, fmt.Errorf("failed to parse hunk count: %w", err)
 					}
 				}
 
@@ -148,6 +164,7 @@ func ParseDiff(diffOutput string) ([]*Hunk, error) {
 					Dependents:   make(map[string]bool),
 					ChangeType:   changeType,
 					IsNewFile:    isNewFile,
+					File:         fileChanges[currentFile],
 				}
 
 				hunks = append(hunks, hunk)
@@ -161,7 +178,19 @@ func ParseDiff(diffOutput string) ([]*Hunk, error) {
 	// Analyze dependencies between hunks
 	analyzeHunkDependencies(hunks)
 
-	return hunks, nil
+	withHunks := make(map[string]bool, len(hunks))
+	for _, hunk := range hunks {
+		withHunks[hunk.FilePath] = true
+	}
+
+	var headerOnly []*FileChange
+	for _, change := range changes {
+		if !withHunks[change.Path] {
+			headerOnly = append(headerOnly, change)
+		}
+	}
+
+	return hunks, headerOnly, nil
 }
 
 // getHunkContext extracts surrounding code context for better AI understanding.
@@ -614,6 +614,42 @@ func parseHunkContent(hunk *Hunk) ([]string, []string, []string) {
 	return additions, deletions, contextLines
 }
 
+// StageHunks stages the hunks of a working tree diff in the repository at
+// workDir, like 'git add --patch'. Unlike ApplyHunks, the hunks are already
+// applied to the working tree, so only the index is updated and the working
+// tree is never modified.
+func StageHunks(workDir string, hunkIDs []string, hunksByID map[string]*Hunk, baseDiff string) error {
+	if len(hunkIDs) == 0 {
+		return nil
+	}
+
+	hunks := make([]*Hunk, 0, len(hunkIDs))
+	for _, hunkID := range hunkIDs {
+		hunk, exists := hunksByID[hunkID]
+		if !exists {
+			return fmt.Errorf("hunk ID not found: %s", hunkID)
+		}
+		hunks = append(hunks, hunk)
+	}
+
+	patchContent := createHunkPatch(hunks, baseDiff)
+	if strings.TrimSpace(patchContent) == "" {
+		return fmt.Errorf("could not create patch for the selected hunks")
+	}
+
+	// the selected hunks may skip hunks before them, recount lets git locate
+	// them by their context instead of the line counts
+	cmd := exec.Command("git", "apply", "--cached", "--recount", "--whitespace=nowarn", "-")
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(patchContent)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage hunks: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
 // ApplyHunksWithFallback applies hunks using the hunk-based approach only.
 func ApplyHunksWithFallback(hunkIDs []string, hunksByID map[string]*Hunk, baseDiff string) error {
 	return ApplyHunks(hunkIDs, hunksByID, baseDiff)
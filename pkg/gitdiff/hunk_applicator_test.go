@@ -1,6 +1,10 @@
 package gitdiff
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -300,3 +304,74 @@ func TestApplyHunksEmpty(t *testing.T) {
 		t.Errorf("Expected no error for empty hunk list, got: %v", err)
 	}
 }
+
+func TestStageHunks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return string(out)
+	}
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "file.txt")
+	git("commit", "-q", "-m", "initial")
+
+	lines[1] = "line 2 changed"
+	lines[27] = "line 28 changed"
+	modified := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(modified), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	diff := git("diff")
+	hunks, err := ParseDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(hunks))
+	}
+
+	hunksByID := make(map[string]*Hunk)
+	for _, hunk := range hunks {
+		hunksByID[hunk.ID] = hunk
+	}
+
+	// stage only the second hunk
+	if err := StageHunks(dir, []string{hunks[1].ID}, hunksByID, diff); err != nil {
+		t.Fatalf("StageHunks() error = %v", err)
+	}
+
+	staged := git("diff", "--cached")
+	if !strings.Contains(staged, "+line 28 changed") || strings.Contains(staged, "+line 2 changed") {
+		t.Errorf("unexpected staged diff:\n%s", staged)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != modified {
+		t.Error("working tree was modified")
+	}
+}
@@ -0,0 +1,134 @@
+package gitdiff
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Ignore matches paths against patterns in the gitignore syntax, where the
+// last matching pattern decides and a pattern prefixed with "!" includes the
+// path again.
+type Ignore struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	regex  *regexp.Regexp
+	negate bool
+}
+
+// NewIgnore returns an Ignore for the patterns, e.g. the lines of a
+// gitignore-like file. Blank lines and comments are skipped.
+func NewIgnore(patterns ...string) *Ignore {
+	ignore := &Ignore{}
+	ignore.Add(patterns...)
+	return ignore
+}
+
+// Add adds the patterns, which take precedence over the existing ones.
+func (i *Ignore) Add(patterns ...string) {
+	for _, pattern := range patterns {
+		if rule, ok := parseIgnorePattern(pattern); ok {
+			i.rules = append(i.rules, rule)
+		}
+	}
+}
+
+// Match reports whether the path, relative to the repository root, is
+// ignored. Paths in an ignored directory are ignored too.
+func (i *Ignore) Match(path string) bool {
+	ignored := false
+	for _, rule := range i.rules {
+		if rule.regex.MatchString(path) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func parseIgnorePattern(pattern string) (ignoreRule, bool) {
+	pattern = strings.TrimRight(strings.TrimSuffix(pattern, "\r"), " ")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		// escaped "#" or "!"
+		pattern = pattern[1:]
+	}
+
+	// a pattern with a trailing slash only matches directories, so paths in them
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	// a pattern with a slash is relative to the root, otherwise it matches at
+	// any level
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return ignoreRule{}, false
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	sb.WriteString(ignoreGlobToRegex(pattern))
+	if dirOnly {
+		sb.WriteString("/.+$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+
+	regex, err := regexp.Compile(sb.String())
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.regex = regex
+
+	return rule, true
+}
+
+func ignoreGlobToRegex(pattern string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
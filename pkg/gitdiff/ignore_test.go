@@ -0,0 +1,84 @@
+package gitdiff
+
+import "testing"
+
+func TestIgnoreMatch(t *testing.T) {
+	ignore := NewIgnore(
+		"# generated code",
+		"*.pb.go",
+		"/vendor/",
+		"docs/**/*.snap",
+		"",
+		"*.lock*",
+		"!keep.lock",
+		"api/client?.go",
+		"gen/[a-c]*.ts",
+	)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"api/v1/service.pb.go", true},
+		{"service.pb.go", true},
+		{"service.go", false},
+		{"vendor/github.com/x/y.go", true},
+		{"internal/vendor/y.go", false},
+		{"vendor", false},
+		{"docs/a/b/page.snap", true},
+		{"docs/page.snap", true},
+		{"src/page.snap", false},
+		{"yarn.lock", true},
+		{"web/Cargo.lock", true},
+		{"keep.lock", false},
+		{"api/client1.go", true},
+		{"api/client10.go", false},
+		{"gen/api.ts", true},
+		{"gen/dto.ts", false},
+	}
+
+	for _, tt := range tests {
+		if got := ignore.Match(tt.path); got != tt.expected {
+			t.Errorf("Match(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestIgnoreAddPrecedence(t *testing.T) {
+	ignore := NewIgnore("*.gen.go")
+	ignore.Add("!models.gen.go")
+
+	if !ignore.Match("api.gen.go") {
+		t.Error("Expected api.gen.go to be ignored")
+	}
+	if ignore.Match("pkg/models.gen.go") {
+		t.Error("Expected the later negation to include models.gen.go")
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tapi/api.pb.go\x00-\t-\tlogo.png\x000\t0\t\x00old.go\x00new.go\x00"
+
+	stats, err := ParseNumstat(output)
+	if err != nil {
+		t.Fatalf("ParseNumstat returned error: %v", err)
+	}
+
+	expected := []FileStat{
+		{Path: "api/api.pb.go", Added: 10, Deleted: 2},
+		{Path: "logo.png", Binary: true},
+		{Path: "new.go", OldPath: "old.go"},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("Expected %d stats, got %d: %v", len(expected), len(stats), stats)
+	}
+	for i := range expected {
+		if stats[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], stats[i])
+		}
+	}
+
+	if got := stats[0].Regenerated(); got != "regenerated api/api.pb.go (+10/−2 lines)" {
+		t.Errorf("Unexpected description: %q", got)
+	}
+}
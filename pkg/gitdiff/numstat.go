@@ -0,0 +1,72 @@
+package gitdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FileStat is the number of added and deleted lines of a changed file, as
+// reported by 'git diff --numstat'.
+type FileStat struct {
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"` // only for renames and copies
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Binary  bool   `json:"binary"`
+}
+
+// Regenerated describes the change of a file which is left out of the diff,
+// e.g. "regenerated api/api.pb.go (+120/−30 lines)".
+func (s FileStat) Regenerated() string {
+	if s.Binary {
+		return fmt.Sprintf("regenerated %s (binary)", s.Path)
+	}
+	return fmt.Sprintf("regenerated %s (+%d/−%d lines)", s.Path, s.Added, s.Deleted)
+}
+
+// ParseNumstat parses the output of 'git diff --numstat -z'.
+func ParseNumstat(output string) ([]FileStat, error) {
+	var stats []FileStat
+
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		if strings.TrimSpace(fields[i]) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimLeft(fields[i], "\n"), "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid numstat line: %q", fields[i])
+		}
+
+		var stat FileStat
+		if parts[0] == "-" && parts[1] == "-" {
+			stat.Binary = true
+		} else {
+			added, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid numstat line: %q", fields[i])
+			}
+			deleted, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid numstat line: %q", fields[i])
+			}
+			stat.Added, stat.Deleted = added, deleted
+		}
+
+		stat.Path = parts[2]
+		if stat.Path == "" {
+			// renames and copies are followed by the old and the new path
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("invalid numstat rename: %q", fields[i])
+			}
+			stat.OldPath, stat.Path = fields[i+1], fields[i+2]
+			i += 2
+		}
+
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,94 @@
+package gitdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RevertSimilarityThreshold is the similarity above which a diff is
+// considered to revert a commit.
+const RevertSimilarityThreshold = 0.9
+
+// RevertMinChangedLines is the minimum number of added and removed lines of a
+// diff considered to revert a commit. Smaller diffs, like flipping a single
+// line back, match the inverse of unrelated commits too easily.
+const RevertMinChangedLines = 4
+
+// changedLines returns the added and removed lines of a diff as keys with
+// the file path, with the whitespace removed the way git patch-id does. When
+// inverse is true, added lines are counted as removed and the other way round.
+func changedLines(diff string, inverse bool) map[string]int {
+	lines := make(map[string]int)
+	currentFile := ""
+	// the file headers come before the first hunk of each file, the removed
+	// lines of the hunks may start with "-- " too
+	inHeader := false
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			currentFile = ""
+			if fields := strings.Fields(line); len(fields) >= 4 {
+				currentFile = stripGitDiffPrefix(fields[3])
+			}
+			inHeader = true
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+		case inHeader:
+			// file headers
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+			sign := line[0]
+			if inverse {
+				if sign == '+' {
+					sign = '-'
+				} else {
+					sign = '+'
+				}
+			}
+
+			content := strings.Map(func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}, line[1:])
+
+			lines[currentFile+"\x00"+string(sign)+content]++
+		}
+	}
+
+	return lines
+}
+
+// RevertSimilarity returns how similar the diff is to the inverse of the
+// commit diff, between 0 and 1. An exact revert of the commit returns 1.
+func RevertSimilarity(diff, commitDiff string) float64 {
+	changes := changedLines(diff, false)
+	inverse := changedLines(commitDiff, true)
+
+	var total, common int
+	for key, count := range changes {
+		total += count
+		common += min(count, inverse[key])
+	}
+	for _, count := range inverse {
+		total += count
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(2*common) / float64(total)
+}
+
+// IsRevert reports whether the diff reverts the commit diff: it is large
+// enough, and similar enough to the inverse of the commit diff.
+func IsRevert(diff, commitDiff string) bool {
+	var changed int
+	for _, count := range changedLines(diff, false) {
+		changed += count
+	}
+
+	return changed >= RevertMinChangedLines && RevertSimilarity(diff, commitDiff) >= RevertSimilarityThreshold
+}
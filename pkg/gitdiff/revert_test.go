@@ -0,0 +1,131 @@
+package gitdiff
+
+import "testing"
+
+func TestRevertSimilarity(t *testing.T) {
+	commitDiff := `diff --git a/main.go b/main.go
+index 1234567..abcdefg 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+-const name = "old"
++const name = "new"
++const debug = true
+`
+
+	revertDiff := `diff --git a/main.go b/main.go
+index abcdefg..1234567 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,3 @@
+ package main
+-const name = "new"
+-const debug = true
++const name  =  "old"
+`
+
+	if got := RevertSimilarity(revertDiff, commitDiff); got != 1 {
+		t.Errorf("Expected exact revert similarity 1, got %f", got)
+	}
+
+	if got := RevertSimilarity(commitDiff, commitDiff); got != 0 {
+		t.Errorf("Expected the commit itself to have similarity 0, got %f", got)
+	}
+
+	partialDiff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,3 @@
+ package main
+-const debug = true
+`
+	if got := RevertSimilarity(partialDiff, commitDiff); got >= RevertSimilarityThreshold {
+		t.Errorf("Expected partial revert below the threshold, got %f", got)
+	}
+
+	otherFileDiff := `diff --git a/other.go b/other.go
+--- a/other.go
++++ b/other.go
+@@ -1,4 +1,3 @@
+-const name = "new"
+-const debug = true
++const name = "old"
+`
+	if got := RevertSimilarity(otherFileDiff, commitDiff); got != 0 {
+		t.Errorf("Expected changes of another file to have similarity 0, got %f", got)
+	}
+
+	// the lines of the hunks starting with "--- " or "+++ " aren't file headers
+	sqlDiff := `diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,2 +1,3 @@
++-- users
+ CREATE TABLE users (id INT);
++++ counter
+`
+	sqlRevertDiff := `diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,3 +1,2 @@
+--- users
+ CREATE TABLE users (id INT);
+-++ counter
+`
+	if got := RevertSimilarity(sqlRevertDiff, sqlDiff); got != 1 {
+		t.Errorf("Expected the revert of comment lines to have similarity 1, got %f", got)
+	}
+}
+
+func TestIsRevert(t *testing.T) {
+	commitDiff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@
+ package main
+-const name = "old"
+-const debug = false
++const name = "new"
++const debug = true
+`
+
+	revertDiff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@
+ package main
+-const name = "new"
+-const debug = true
++const name = "old"
++const debug = false
+`
+	if !IsRevert(revertDiff, commitDiff) {
+		t.Error("Expected the exact inverse to be a revert")
+	}
+
+	// flipping a single line back is the exact inverse of the commit, but
+	// too small to tell a revert from an unrelated change
+	smallCommitDiff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@
+ package main
+-const debug = false
++const debug = true
+`
+	smallDiff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@
+ package main
+-const debug = true
++const debug = false
+`
+	if got := RevertSimilarity(smallDiff, smallCommitDiff); got != 1 {
+		t.Errorf("Expected the small inverse similarity 1, got %f", got)
+	}
+	if IsRevert(smallDiff, smallCommitDiff) {
+		t.Error("Expected a single flipped line not to be a revert")
+	}
+}
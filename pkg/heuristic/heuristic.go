@@ -0,0 +1,306 @@
+// Package heuristic generates commit messages from a diff without a model:
+// the type is inferred from the changed paths, the scope from their common
+// directory, and the description from the file changes and the top-level
+// symbols added or removed.
+package heuristic
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/duke-git/lancet/v2/slice"
+
+	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/gitdiff"
+)
+
+// maxListed is the maximum number of files or symbols named in a message.
+const maxListed = 2
+
+// symbolRegexes match the declarations of top-level symbols, unindented, in
+// the added and removed lines of a diff.
+var symbolRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`),                                          // Go
+	regexp.MustCompile(`^type\s+([A-Za-z_]\w*)\s`),                                                         // Go
+	regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+([A-Za-z_$][\w$]*)`),      // JavaScript
+	regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`),         // JavaScript, Python
+	regexp.MustCompile(`^(?:export\s+)?(?:interface|enum|type)\s+([A-Za-z_$][\w$]*)`),                      // TypeScript
+	regexp.MustCompile(`^export\s+(?:const|let|var)\s+([A-Za-z_$][\w$]*)`),                                 // JavaScript
+	regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z_]\w*)`),                                               // Python
+	regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:fn|struct|enum|trait)\s+([A-Za-z_]\w*)`), // Rust
+}
+
+// Symbols are the top-level symbols declared in the added and removed lines
+// of a diff. A symbol in both is changed rather than added or removed.
+type Symbols struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// ParseSymbols returns the top-level symbols of the diff, in the order of the
+// diff.
+func ParseSymbols(diff string) Symbols {
+	var added, removed []string
+
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ") {
+			continue
+		}
+
+		var symbols *[]string
+		switch {
+		case strings.HasPrefix(line, "+"):
+			symbols = &added
+		case strings.HasPrefix(line, "-"):
+			symbols = &removed
+		default:
+			continue
+		}
+
+		for _, re := range symbolRegexes {
+			if match := re.FindStringSubmatch(line[1:]); match != nil {
+				if !slices.Contains(*symbols, match[1]) {
+					*symbols = append(*symbols, match[1])
+				}
+				break
+			}
+		}
+	}
+
+	var s Symbols
+	for _, symbol := range added {
+		if slices.Contains(removed, symbol) {
+			s.Changed = append(s.Changed, symbol)
+		} else {
+			s.Added = append(s.Added, symbol)
+		}
+	}
+	for _, symbol := range removed {
+		if !slices.Contains(added, symbol) {
+			s.Removed = append(s.Removed, symbol)
+		}
+	}
+
+	return s
+}
+
+// Message infers the message of the changes of the diff: its conventional
+// commit type, the scope and the description.
+func Message(diff string) commit.Message {
+	changes := gitdiff.ParseFileChanges(diff)
+	if len(changes) == 0 {
+		return commit.Message{}
+	}
+
+	symbols := ParseSymbols(diff)
+	t := inferType(changes, symbols)
+
+	return commit.Message{
+		Type:          t,
+		Scope:         inferScope(changes, t),
+		CommitMessage: describe(changes, symbols),
+	}
+}
+
+// Render renders the message according to the commit type. Gitmoji messages
+// use the gitmoji of the conventional type, and the messages of the simple
+// and the user-defined formats are capitalized descriptions.
+func Render(m commit.Message, t commit.Type) string {
+	switch t {
+	case commit.ConventionalType:
+		return m.ToString()
+	case commit.GitmojiType:
+		gitmoji, ok := typeGitmojis[m.Type]
+		if !ok {
+			gitmoji = ":wrench:"
+		}
+		return commit.Message{Type: gitmoji, Scope: m.Scope, CommitMessage: m.CommitMessage}.ToString()
+	default:
+		return capitalize(m.CommitMessage)
+	}
+}
+
+var typeGitmojis = map[string]string{
+	"feat":     ":sparkles:",
+	"fix":      ":bug:",
+	"docs":     ":memo:",
+	"test":     ":white_check_mark:",
+	"ci":       ":construction_worker:",
+	"build":    ":building_construction:",
+	"refactor": ":recycle:",
+	"chore":    ":wrench:",
+}
+
+// inferType returns the type shared by all changed paths, e.g. docs when
+// only documentation changed, or else the type of the change: feat for new
+// files or symbols, refactor for renames and removals, chore otherwise.
+func inferType(changes []*gitdiff.FileChange, symbols Symbols) string {
+	for _, pathType := range []struct {
+		name  string
+		match func(string) bool
+	}{
+		{"docs", isDocs},
+		{"test", isTest},
+		{"ci", isCI},
+		{"build", isBuild},
+	} {
+		if slices.ContainsFunc(changes, func(c *gitdiff.FileChange) bool { return pathType.match(c.Path) }) &&
+			!slices.ContainsFunc(changes, func(c *gitdiff.FileChange) bool { return !pathType.match(c.Path) }) {
+			return pathType.name
+		}
+	}
+
+	switch {
+	case allStatus(changes, "A") || len(symbols.Added) > 0:
+		return "feat"
+	case allStatus(changes, "R") || allStatus(changes, "D") || (len(symbols.Removed) > 0 && len(symbols.Changed) == 0):
+		return "refactor"
+	default:
+		return "chore"
+	}
+}
+
+func isDocs(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".mdx", ".rst", ".adoc", ".txt":
+		return true
+	}
+	base := strings.ToUpper(path.Base(p))
+	return strings.HasPrefix(p, "docs/") || strings.Contains(p, "/docs/") ||
+		strings.HasPrefix(base, "LICENSE") || strings.HasPrefix(base, "CHANGELOG")
+}
+
+func isTest(p string) bool {
+	base := path.Base(p)
+	return strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(p, "test/") || strings.HasPrefix(p, "tests/") ||
+		strings.Contains(p, "/test/") || strings.Contains(p, "/tests/") || strings.Contains(p, "__tests__/") ||
+		strings.Contains(p, "/testdata/") || strings.HasPrefix(p, "testdata/")
+}
+
+func isCI(p string) bool {
+	return strings.HasPrefix(p, ".github/") || strings.HasPrefix(p, ".circleci/") ||
+		strings.HasPrefix(p, ".gitlab/") || p == ".gitlab-ci.yml" || p == ".travis.yml" ||
+		p == "Jenkinsfile" || p == "azure-pipelines.yml"
+}
+
+func isBuild(p string) bool {
+	switch path.Base(p) {
+	case "Makefile", "GNUmakefile", "Dockerfile", "docker-compose.yml", "Taskfile.yml", "justfile",
+		"go.mod", "go.sum", "package.json", "Cargo.toml", "pyproject.toml", "setup.py", "build.gradle", "pom.xml",
+		".goreleaser.yml", ".goreleaser.yaml":
+		return true
+	}
+	return strings.HasSuffix(p, ".mk")
+}
+
+func allStatus(changes []*gitdiff.FileChange, status string) bool {
+	for _, c := range changes {
+		if c.Status != status {
+			return false
+		}
+	}
+	return true
+}
+
+// genericDirectories are directories which don't make a useful scope.
+var genericDirectories = []string{"src", "lib", "pkg", "internal", "app", "docs", "test", "tests", "testdata", ".github", "workflows"}
+
+// inferScope returns the last element of the common directory of the changed
+// files, unless it is generic or repeats the type.
+func inferScope(changes []*gitdiff.FileChange, t string) string {
+	var common []string
+	for i, c := range changes {
+		dir := strings.Split(path.Dir(c.Path), "/")
+		if dir[0] == "." {
+			return ""
+		}
+		if i == 0 {
+			common = dir
+			continue
+		}
+
+		n := 0
+		for n < len(common) && n < len(dir) && common[n] == dir[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	if len(common) == 0 {
+		return ""
+	}
+
+	scope := common[len(common)-1]
+	if slices.Contains(genericDirectories, strings.ToLower(scope)) {
+		return ""
+	}
+	if scope = strings.TrimPrefix(scope, "."); scope == t {
+		return ""
+	}
+	return scope
+}
+
+// describe returns the description of the changes: the symbols added,
+// removed or changed when there are any, or else what happened to the files.
+func describe(changes []*gitdiff.FileChange, symbols Symbols) string {
+	switch {
+	case len(symbols.Added) > 0 && len(symbols.Removed) == 0:
+		return "add " + list(symbols.Added)
+	case len(symbols.Removed) > 0 && len(symbols.Added) == 0 && len(symbols.Changed) == 0:
+		return "remove " + list(symbols.Removed)
+	case len(symbols.Added) > 0:
+		return fmt.Sprintf("add %s and remove %s", list(symbols.Added), list(symbols.Removed))
+	}
+
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		files = append(files, path.Base(c.Path))
+	}
+
+	switch {
+	case allStatus(changes, "A"):
+		return "add " + list(files)
+	case allStatus(changes, "D"):
+		return "remove " + list(files)
+	case len(changes) == 1 && changes[0].Status == "R" && path.Dir(changes[0].OldPath) == path.Dir(changes[0].Path):
+		return fmt.Sprintf("rename %s to %s", path.Base(changes[0].OldPath), files[0])
+	case allStatus(changes, "R"):
+		return fmt.Sprintf("move %s to %s", list(files), path.Dir(changes[0].Path))
+	case len(changes) == 1 && changes[0].ModeChanged() && changes[0].Added == 0 && changes[0].Deleted == 0:
+		if strings.HasSuffix(changes[0].NewMode, "755") {
+			return fmt.Sprintf("make %s executable", files[0])
+		}
+		return fmt.Sprintf("change mode of %s", files[0])
+	case len(symbols.Changed) > 0:
+		return "update " + list(symbols.Changed)
+	default:
+		return "update " + list(files)
+	}
+}
+
+// list names the items, e.g. "a and b", or "a, b and 3 more".
+func list(items []string) string {
+	items = slice.Unique(items)
+
+	switch {
+	case len(items) == 1:
+		return items[0]
+	case len(items) <= maxListed:
+		return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+	default:
+		return fmt.Sprintf("%s and %d more", strings.Join(items[:maxListed], ", "), len(items)-maxListed)
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,120 @@
+package heuristic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zbiljic/kai/pkg/commit"
+)
+
+func TestParseSymbols(t *testing.T) {
+	diff := `diff --git a/api/users.go b/api/users.go
+--- a/api/users.go
++++ b/api/users.go
+@@ -1,9 +1,12 @@
++func (s *Server) ListUsers(w http.ResponseWriter, r *http.Request) {
++type UserFilter struct {
+-func validate(id string) error {
++func validate(id string, strict bool) error {
+-func oldHelper() {
++	func nested() {
+`
+
+	expected := Symbols{
+		Added:   []string{"ListUsers", "UserFilter"},
+		Removed: []string{"oldHelper"},
+		Changed: []string{"validate"},
+	}
+	if got := ParseSymbols(diff); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected string
+	}{
+		{
+			name: "added symbols",
+			diff: `diff --git a/services/api/users.go b/services/api/users.go
+--- a/services/api/users.go
++++ b/services/api/users.go
+@@ -1 +1,3 @@
++func ListUsers() {
++}
+`,
+			expected: "feat(api): add ListUsers",
+		},
+		{
+			name: "docs",
+			diff: `diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1 +1 @@
+-old
++new
+`,
+			expected: "docs: update README.md",
+		},
+		{
+			name: "tests",
+			diff: `diff --git a/pkg/deps/deps_test.go b/pkg/deps/deps_test.go
+--- a/pkg/deps/deps_test.go
++++ b/pkg/deps/deps_test.go
+@@ -1 +1 @@
+-	old
++	new
+`,
+			expected: "test(deps): update deps_test.go",
+		},
+		{
+			name: "ci",
+			diff: `diff --git a/.github/workflows/ci.yml b/.github/workflows/ci.yml
+--- a/.github/workflows/ci.yml
++++ b/.github/workflows/ci.yml
+@@ -1 +1 @@
+-old
++new
+`,
+			expected: "ci: update ci.yml",
+		},
+		{
+			name: "rename",
+			diff: `diff --git a/cmd/old.go b/cmd/new.go
+similarity index 100%
+rename from cmd/old.go
+rename to cmd/new.go
+`,
+			expected: "refactor(cmd): rename old.go to new.go",
+		},
+		{
+			name: "mode",
+			diff: `diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+`,
+			expected: "chore: make run.sh executable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(Message(tt.diff), commit.ConventionalType); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	m := commit.Message{Type: "feat", Scope: "api", CommitMessage: "add ListUsers"}
+
+	if got := Render(m, commit.GitmojiType); got != ":sparkles: (api): add ListUsers" {
+		t.Errorf("Unexpected gitmoji message %q", got)
+	}
+	if got := Render(m, commit.SimpleType); got != "Add ListUsers" {
+		t.Errorf("Unexpected simple message %q", got)
+	}
+}
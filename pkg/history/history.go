@@ -0,0 +1,218 @@
+// Package history selects previous commits of a repository as examples of
+// its commit message style, ranked by their relevance to the current changes.
+package history
+
+import (
+	"math"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogFormat is the 'git log' format parsed by ParseLog, used together with
+// --name-only.
+const LogFormat = "%x1e%H%x1f%ae%x1f%ct%x1f%s"
+
+// RecencyHalfLife is the age at which the recency of a commit counts half.
+const RecencyHalfLife = 90 * 24 * time.Hour
+
+// Score weights of the ranking.
+const (
+	pathWeight      = 3.0
+	directoryWeight = 2.0
+	recencyWeight   = 1.0
+	authorWeight    = 0.5
+)
+
+// Commit is a commit of the log with the files it changed.
+type Commit struct {
+	Hash        string    `json:"hash"`
+	AuthorEmail string    `json:"author_email"`
+	Time        time.Time `json:"time"`
+	Subject     string    `json:"subject"`
+	Files       []string  `json:"files"`
+}
+
+// Example is a commit selected as an example, with its relevance score.
+type Example struct {
+	Commit
+	Score float64 `json:"score"`
+}
+
+// LogArgs returns the 'git log' arguments listing the latest commits, without
+// merges, changing the paths, in the format parsed by ParseLog. With
+// --full-diff the commits list all the files they changed, not only the ones
+// matching the paths.
+func LogArgs(paths []string, maxCommits int) []string {
+	args := []string{
+		"log", "--no-merges", "--name-only", "--no-renames", "--full-diff",
+		"--format=" + LogFormat,
+		"-n", strconv.Itoa(maxCommits),
+		"--",
+	}
+	return append(args, paths...)
+}
+
+// ParseLog parses the output of 'git log --name-only' with the LogFormat.
+func ParseLog(output string) []Commit {
+	var commits []Commit
+
+	for _, record := range strings.Split(output, "\x1e") {
+		header, files, _ := strings.Cut(record, "\n")
+
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+
+		c := Commit{
+			Hash:        fields[0],
+			AuthorEmail: fields[1],
+			Subject:     strings.TrimSpace(fields[3]),
+		}
+		if seconds, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			c.Time = time.Unix(seconds, 0)
+		}
+
+		for _, file := range strings.Split(files, "\n") {
+			if file = strings.TrimSpace(file); file != "" {
+				c.Files = append(c.Files, file)
+			}
+		}
+
+		commits = append(commits, c)
+	}
+
+	return commits
+}
+
+// isExcluded reports whether the commit is not a useful example: a fixup or
+// squash commit meant to be autosquashed, or a merge described by git.
+func isExcluded(c Commit) bool {
+	for _, prefix := range []string{"fixup!", "squash!", "amend!", "Merge branch ", "Merge pull request ", "Merge remote-tracking branch "} {
+		if strings.HasPrefix(c.Subject, prefix) {
+			return true
+		}
+	}
+	return c.Subject == ""
+}
+
+// Rank scores the commits by their relevance to the changed files: the
+// overlap of the changed paths, the proximity of their directories, the
+// recency of the commit and whether it is by the same author. It returns the
+// top k relevant commits, in a stable order, leaving out fixup commits and
+// repeated subjects.
+func Rank(commits []Commit, files []string, authorEmail string, now time.Time, k int) []Example {
+	var examples []Example
+	seen := make(map[string]bool)
+
+	for _, c := range commits {
+		if isExcluded(c) || seen[c.Subject] {
+			continue
+		}
+
+		pathScore := pathOverlap(files, c.Files)
+		directoryScore := directoryProximity(files, c.Files)
+		if pathScore == 0 && directoryScore == 0 {
+			continue
+		}
+		seen[c.Subject] = true
+
+		score := pathWeight*pathScore + directoryWeight*directoryScore
+		if age := now.Sub(c.Time); !c.Time.IsZero() && age > 0 {
+			score += recencyWeight * math.Pow(0.5, float64(age)/float64(RecencyHalfLife))
+		} else {
+			score += recencyWeight
+		}
+		if authorEmail != "" && strings.EqualFold(c.AuthorEmail, authorEmail) {
+			score += authorWeight
+		}
+
+		examples = append(examples, Example{Commit: c, Score: score})
+	}
+
+	slices.SortStableFunc(examples, func(a, b Example) int {
+		switch {
+		case a.Score != b.Score:
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		case !a.Time.Equal(b.Time):
+			return b.Time.Compare(a.Time)
+		default:
+			return strings.Compare(a.Hash, b.Hash)
+		}
+	})
+
+	if len(examples) > k {
+		examples = examples[:k]
+	}
+
+	return examples
+}
+
+// pathOverlap returns the Jaccard similarity of the changed files and the
+// files of the commit, so commits changing the same files, and little else,
+// score highest.
+func pathOverlap(files, commitFiles []string) float64 {
+	if len(files) == 0 || len(commitFiles) == 0 {
+		return 0
+	}
+
+	common := 0
+	for _, file := range files {
+		if slices.Contains(commitFiles, file) {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(files)+len(commitFiles)-common)
+}
+
+// directoryProximity returns how close the files of the commit are to the
+// changed files, averaged over the changed files: 1 for a file in the same
+// directory, less for a common parent directory, and 0 without one.
+func directoryProximity(files, commitFiles []string) float64 {
+	if len(files) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, file := range files {
+		dir := splitDir(file)
+
+		best := 0.0
+		for _, commitFile := range commitFiles {
+			commitDir := splitDir(commitFile)
+
+			common := 0
+			for common < len(dir) && common < len(commitDir) && dir[common] == commitDir[common] {
+				common++
+			}
+
+			depth := max(len(dir), len(commitDir))
+			switch {
+			case depth == 0:
+				// both at the root of the repository
+				best = max(best, 1)
+			case common > 0:
+				best = max(best, float64(common)/float64(depth))
+			}
+		}
+
+		total += best
+	}
+
+	return total / float64(len(files))
+}
+
+func splitDir(file string) []string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return nil
+	}
+	return strings.Split(dir, "/")
+}
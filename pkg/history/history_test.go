@@ -0,0 +1,103 @@
+package history
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLog(t *testing.T) {
+	output := "\x1eaaa\x1fme@example.com\x1f1700000000\x1ffeat(api): add users endpoint\n\napi/users.go\napi/router.go\n" +
+		"\x1ebbb\x1fother@example.com\x1f1690000000\x1fchore: empty commit\n"
+
+	commits := ParseLog(output)
+	expected := []Commit{
+		{Hash: "aaa", AuthorEmail: "me@example.com", Time: time.Unix(1700000000, 0), Subject: "feat(api): add users endpoint", Files: []string{"api/users.go", "api/router.go"}},
+		{Hash: "bbb", AuthorEmail: "other@example.com", Time: time.Unix(1690000000, 0), Subject: "chore: empty commit"},
+	}
+	if !reflect.DeepEqual(commits, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, commits)
+	}
+}
+
+func TestRank(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	commits := []Commit{
+		{Hash: "1", Subject: "fixup! feat(api): add users endpoint", Time: now.Add(-day), Files: []string{"api/users.go"}},
+		{Hash: "2", Subject: "docs: update readme", Time: now.Add(-day), Files: []string{"README.md"}},
+		{Hash: "3", Subject: "feat(api): add pagination", Time: now.Add(-10 * day), Files: []string{"api/router.go"}},
+		{Hash: "4", Subject: "fix(api): validate user ids", Time: now.Add(-400 * day), Files: []string{"api/users.go"}},
+		{Hash: "5", Subject: "refactor: move everything", Time: now.Add(-2 * day), Files: []string{"api/users.go", "web/a.ts", "web/b.ts", "web/c.ts"}},
+		{Hash: "6", Subject: "fix(api): validate user ids", Time: now.Add(-500 * day), Files: []string{"api/users.go"}},
+		{Hash: "7", Subject: "feat(api/v2): add handler", Time: now.Add(-3 * day), Files: []string{"api/v2/handler.go"}, AuthorEmail: "ME@example.com"},
+	}
+
+	examples := Rank(commits, []string{"api/users.go"}, "me@example.com", now, 4)
+
+	var hashes []string
+	for _, e := range examples {
+		hashes = append(hashes, e.Hash)
+	}
+
+	// the same file ranks first even when old, then the same directory, and
+	// fixups, unrelated and repeated commits are left out
+	expected := []string{"4", "5", "3", "7"}
+	if !reflect.DeepEqual(hashes, expected) {
+		t.Errorf("Expected %v, got %v (%+v)", expected, hashes, examples)
+	}
+
+	again := Rank(commits, []string{"api/users.go"}, "me@example.com", now, 4)
+	if !reflect.DeepEqual(examples, again) {
+		t.Error("Expected the ranking to be deterministic")
+	}
+}
+
+func TestLogArgs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return string(out)
+	}
+
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test")
+	for _, file := range []string{"api/users.go", "api/router.go", "README.md"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "feat(api): add users endpoint")
+
+	commits := ParseLog(git(LogArgs([]string{"api/users.go"}, 10)...))
+	if len(commits) != 1 {
+		t.Fatalf("Expected 1 commit, got %+v", commits)
+	}
+
+	// all the files of the commit, not only the one matching the paths
+	expected := []string{"README.md", "api/router.go", "api/users.go"}
+	if !reflect.DeepEqual(commits[0].Files, expected) {
+		t.Errorf("Expected files %v, got %v", expected, commits[0].Files)
+	}
+	if commits[0].Subject != "feat(api): add users endpoint" || commits[0].AuthorEmail != "test@example.com" {
+		t.Errorf("Unexpected commit %+v", commits[0])
+	}
+}
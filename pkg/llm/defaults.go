@@ -20,10 +20,95 @@ var (
 3. Output only the commit message without any explanations
 4. Follow the format: %s
 `
+	PromptLanguageFormat        = "Write the commit message in %s, but keep the type, the scope and the other keywords of the format unchanged."
 	PromptMaxLengthFormat       = "Commit message must be a maximum of %d characters."
 	PromptCodeDiffFormat        = "Code diff:\n```diff\n%s\n```\n"
 	PromptPreviousCommitsFormat = `Here are some previous commit messages for similar changes (use these as a style reference):
 %s
+`
+	PromptAmendFormat = `The code diff below is the amended version of an existing commit, which has this message:
+%s
+
+Write a new commit message for the whole diff, keeping what is still accurate in the existing message.
+`
+	PromptRewordFormat = `The code diff below is an existing commit, which has this message:
+%s
+
+Write a new commit message for the diff, keeping what is still accurate in the existing message.
+`
+	PromptSiblingCommitsFormat = `The commit is part of a series with these other commits (use them as context, don't describe their changes):
+%s
+`
+	PromptDependencyChangesFormat = `The diff changes these dependencies (lockfiles are left out of the diff):
+%s
+`
+	PromptExcludedFilesFormat = `These files changed too, but are left out of the diff (lockfiles, generated or vendored code):
+%s
+`
+	PromptChangeSummaryFormat = `Summary of the changed files (status as with git diff --name-status, R for renames with their similarity, mode changes, binary sizes, added and deleted lines):
+%s
+`
+	PromptAllowedScopesFormat = `The changed paths belong to these scopes, use one of them as the scope:
+%s
+`
+	PromptSuggestedScopesFormat = `Scopes used in this repository, most relevant to the changes first (use one of them, or no scope, rather than a new one):
+%s
+`
+	PromptSquashIntro   = `Generate a git commit message written in present tense for squash-merging a branch, based on its commits and code diff, with the given specifications below:`
+	PromptSquashDetails = `The message starts with a single subject line describing the whole branch, followed by a blank line and a body summarizing the changes of the branch as a short list.
+Don't list every commit, group related changes together and leave out fixes of changes made within the branch.
+Your entire response will be passed directly into git commit.
+`
+	PromptSquashMaxLengthFormat = "The subject line must be a maximum of %d characters."
+	PromptSquashCommitsFormat   = "Commits of the branch:\n%s\n"
+	PromptBodySystem            = "You are a commit message writer. Output only the requested text without any explanations."
+	PromptBodyLanguageFormat    = "Write the text in %s."
+	PromptMergeFormat           = `Summarize the changes merged by the commit "%s" as the body of its commit message, based on the subjects of the merged commits below.
+Write one to three short sentences or a short list in present tense, without a subject line.
+Your entire response will be added to the commit message below the subject line.
+
+Merged commits:
+%s
+`
+	PromptRevertFormat = `The commit below is being reverted, and the user gave this context for the revert:
+%s
+
+Reverted commit:
+%s
+
+Write one or two short sentences in present tense explaining why the commit is reverted, based only on the given context.
+Your entire response will be added to the body of the revert commit message.
+`
+	PromptResolveSystem = `You are an expert software engineer resolving git merge conflicts.
+Combine the intent of both sides of the conflict, and keep the code consistent with the surrounding code.
+Never leave conflict markers in the resolution.
+`
+	PromptResolveFormat = `Resolve the following conflict in the file %s.
+The "ours" side is the branch being rebased onto or merged into, "theirs" is the change being applied. The base section, if present, is the common ancestor.
+
+Code before the conflict:
+%s
+
+Conflict:
+%s
+
+Code after the conflict:
+%s
+
+Respond in exactly this format, where the code block contains only the lines replacing the whole conflict (including its markers):
+Explanation: <one or two sentences explaining the resolution>
+Resolution:
+%s
+<resolved lines>
+%s
+`
+	PromptRepairFormat = `The following commit message was generated for the code diff below, but it violates these rules:
+%s
+
+Commit message:
+%s
+
+Rewrite the commit message so that it follows all the rules while keeping its meaning.
 `
 )
 
@@ -34,4 +119,9 @@ var commitTypes = map[commit.Type]string{
 		"Choose a type from the allowed types table below that best describes the git diff:",
 		mapToTable(commit.ConventionalCommitTypes),
 	),
+	commit.GitmojiType: fmt.Sprintf(
+		"%s\n%s",
+		"Choose a gitmoji shortcode from the allowed types table below that best describes the git diff:",
+		mapToTable(commit.GitmojiTypes),
+	),
 }
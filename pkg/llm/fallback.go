@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+)
+
+// Compile-time proof of interface implementation.
+var (
+	_ AIPrompt     = (*Fallback)(nil)
+	_ ProviderInfo = (*Fallback)(nil)
+)
+
+// Fallback is a provider which falls back to another provider, e.g. the
+// offline heuristics, when the requests of the primary provider fail because
+// the network is down. Once it fell back, the later requests go to the
+// fallback provider directly. Other errors, like invalid API keys, are
+// returned, unless it falls back on any error.
+type Fallback struct {
+	primary    AIPrompt
+	fallback   AIPrompt
+	onAnyError bool
+
+	mu   sync.Mutex
+	used AIPrompt
+}
+
+func NewFallback(primary, fallback AIPrompt) *Fallback {
+	return &Fallback{
+		primary:  primary,
+		fallback: fallback,
+		used:     primary,
+	}
+}
+
+// NewFallbackOnError returns a provider which falls back to another provider
+// when the requests of the primary provider fail for any reason, e.g. for a
+// provider used without an API key as the last resort.
+func NewFallbackOnError(primary, fallback AIPrompt) *Fallback {
+	f := NewFallback(primary, fallback)
+	f.onAnyError = true
+	return f
+}
+
+func (f *Fallback) String() string {
+	return f.current().String()
+}
+
+// Name returns the name of the provider used by the last request.
+func (f *Fallback) Name() string {
+	used := f.current()
+	if info, ok := used.(ProviderInfo); ok {
+		return info.Name()
+	}
+	return used.String()
+}
+
+// Model returns the model of the provider used by the last request.
+func (f *Fallback) Model() string {
+	if info, ok := f.current().(ProviderInfo); ok {
+		return info.Model()
+	}
+	return ""
+}
+
+// Usage returns the usage of the primary provider.
+func (f *Fallback) Usage() Usage {
+	if info, ok := f.primary.(ProviderInfo); ok {
+		return info.Usage()
+	}
+	return Usage{}
+}
+
+func (f *Fallback) IsAvailable() bool {
+	return f.primary.IsAvailable()
+}
+
+// FellBack reports whether the requests go to the fallback provider.
+func (f *Fallback) FellBack() bool {
+	return f.current() == f.fallback
+}
+
+// current returns the provider the requests go to. The requests may be made
+// concurrently, e.g. by the hook, which doesn't wait for a late request.
+func (f *Fallback) current() AIPrompt {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.used
+}
+
+func (f *Fallback) Generate(ctx context.Context, systemPrompt, userPrompt string, candidateCount int) ([]string, error) {
+	if f.FellBack() {
+		return f.fallback.Generate(ctx, systemPrompt, userPrompt, candidateCount)
+	}
+
+	messages, err := f.primary.Generate(ctx, systemPrompt, userPrompt, candidateCount)
+	if err == nil || ctx.Err() != nil || (!f.onAnyError && !isNetworkError(err)) {
+		return messages, err
+	}
+
+	fallbackMessages, fallbackErr := f.fallback.Generate(ctx, systemPrompt, userPrompt, candidateCount)
+	if fallbackErr != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.used = f.fallback
+	f.mu.Unlock()
+
+	return fallbackMessages, nil
+}
+
+// isNetworkError reports whether the error is a failure to reach the
+// provider, e.g. a DNS lookup or a connection failure, or a timeout.
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
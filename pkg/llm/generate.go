@@ -2,25 +2,53 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/gitdiff"
+	"github.com/zbiljic/kai/pkg/scopes"
 )
 
 // Default values for commit message generation
 const (
-	DefaultMaxCommitsPerFile = 3
 	DefaultMaxTotalCommits   = 10
+	DefaultMaxHistoryCommits = 200
+	DefaultMaxScopeCommits   = 500
 )
 
 // commitType returns a descriptive string for the given commit Type.
 // It utilizes a predefined map to fetch the corresponding description.
 // This helps in providing guidance or context about the chosen commit type.
 func commitType(t commit.Type) string {
+	if f, ok := t.CustomFormat(); ok {
+		return customFormatTypes(f)
+	}
 	return commitTypes[t]
 }
 
+// customFormatTypes returns the allowed types and scopes tables for a
+// user-defined commit format.
+func customFormatTypes(f *commit.Format) string {
+	var content []string
+	if len(f.Types) > 0 {
+		content = append(content, fmt.Sprintf(
+			"%s\n%s",
+			"Choose a type from the allowed types table below that best describes the git diff:",
+			mapToTableWithHeader(f.Types, "Type"),
+		))
+	}
+	if len(f.Scopes) > 0 {
+		content = append(content, fmt.Sprintf(
+			"%s\n%s",
+			"Choose a scope from the allowed scopes table below that best describes the git diff:",
+			mapToTableWithHeader(f.Scopes, "Scope"),
+		))
+	}
+	return strings.Join(content, "\n")
+}
+
 // formatPreviousCommits formats a list of previous commit messages for display in the prompt
 func formatPreviousCommits(messages []string) string {
 	if len(messages) == 0 {
@@ -36,17 +64,121 @@ func formatPreviousCommits(messages []string) string {
 	return strings.Join(formattedMessages, "\n")
 }
 
-func GenerateSystemPrompt(t commit.Type) string {
+// ExcludedFilesSection returns the prompt section listing the changed files
+// left out of the diff, or an empty string without such files.
+func ExcludedFilesSection(excluded []gitdiff.FileStat) string {
+	if len(excluded) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(excluded))
+	for _, stat := range excluded {
+		lines = append(lines, "- "+stat.Regenerated())
+	}
+
+	return fmt.Sprintf(PromptExcludedFilesFormat, strings.Join(lines, "\n"))
+}
+
+// ChangeSummarySection returns the prompt section summarizing the changed
+// files, or an empty string without changes.
+func ChangeSummarySection(changes []*gitdiff.FileChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(PromptChangeSummaryFormat, gitdiff.FormatChangeSummary(changes))
+}
+
+// ScopesSection returns the prompt section with the scopes for the changes:
+// the scopes of the path rules matching the changed files are required, the
+// scopes of the history are suggested. It is empty without scopes.
+func ScopesSection(suggestions []scopes.Suggestion) string {
+	format := PromptSuggestedScopesFormat
+	if matched := scopes.Matched(suggestions); len(matched) > 0 {
+		format, suggestions = PromptAllowedScopesFormat, matched
+	}
+
+	if len(suggestions) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		line := "- " + s.Scope
+		if hint := s.Hint(); hint != "" {
+			line += " (" + hint + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	return fmt.Sprintf(format, strings.Join(lines, "\n"))
+}
+
+// GenerateSystemPrompt generates the system prompt for the commit type. With a
+// language, e.g. "German", the prose of the messages is written in it.
+func GenerateSystemPrompt(t commit.Type, lang string) string {
 	var content []string
 	content = append(content, fmt.Sprintf(PromptSystemFormat, t.CommitFormat()))
+	if lang != "" {
+		content = append(content, fmt.Sprintf(PromptLanguageFormat, lang))
+	}
 	return strings.Join(content, "\n")
 }
 
-func GenerateUserPrompt(t commit.Type, maxLength int, diff string) string {
-	return GenerateUserPromptWithPreviousCommits(t, maxLength, diff, nil)
+// GenerateUserPrompt generates the user prompt for the diff. The extra
+// context sections, e.g. the dependency changes, are placed before the diff.
+func GenerateUserPrompt(t commit.Type, maxLength int, diff string, extraContext ...string) string {
+	return GenerateUserPromptWithPreviousCommits(t, maxLength, diff, nil, extraContext...)
+}
+
+// GenerateUserPromptWithPreviousCommits generates the user prompt for the
+// diff, with the messages of the previous commits as examples of the style of
+// the repository, followed by the extra context sections.
+func GenerateUserPromptWithPreviousCommits(t commit.Type, maxLength int, diff string, previousCommits []string, extraContext ...string) string {
+	var sections []string
+
+	// Add previous commit messages if available
+	if len(previousCommits) > 0 {
+		sections = append(sections, fmt.Sprintf(PromptPreviousCommitsFormat, formatPreviousCommits(previousCommits)))
+	}
+
+	sections = append(sections, extraContext...)
+
+	return generateUserPrompt(t, maxLength, diff, sections...)
+}
+
+// GenerateAmendUserPrompt generates the user prompt for a commit that is
+// being amended. It is the prompt of GenerateUserPromptWithPreviousCommits,
+// with the existing message of the commit as context before the extra context
+// sections.
+func GenerateAmendUserPrompt(t commit.Type, maxLength int, diff, currentMessage string, previousCommits []string, extraContext ...string) string {
+	if currentMessage != "" {
+		extraContext = append([]string{fmt.Sprintf(PromptAmendFormat, currentMessage)}, extraContext...)
+	}
+
+	return GenerateUserPromptWithPreviousCommits(t, maxLength, diff, previousCommits, extraContext...)
 }
 
-func GenerateUserPromptWithPreviousCommits(t commit.Type, maxLength int, diff string, previousCommits []string) string {
+// GenerateRewordUserPrompt generates the user prompt for an existing commit
+// whose message is rewritten, with the messages of the other commits of the
+// series as context.
+func GenerateRewordUserPrompt(t commit.Type, maxLength int, diff, currentMessage string, siblingCommits []string) string {
+	var sections []string
+
+	if len(siblingCommits) > 0 {
+		sections = append(sections, fmt.Sprintf(PromptSiblingCommitsFormat, formatPreviousCommits(siblingCommits)))
+	}
+
+	if currentMessage != "" {
+		sections = append(sections, fmt.Sprintf(PromptRewordFormat, currentMessage))
+	}
+
+	return generateUserPrompt(t, maxLength, diff, sections...)
+}
+
+// generateUserPrompt generates the user prompt for the diff, with the given
+// context sections placed before the diff.
+func generateUserPrompt(t commit.Type, maxLength int, diff string, sections ...string) string {
 	var content []string
 	content = append(content, PromptIntro)
 	content = append(content, "")
@@ -58,9 +190,8 @@ func GenerateUserPromptWithPreviousCommits(t commit.Type, maxLength int, diff st
 	content = append(content, fmt.Sprintf(PromptMaxLengthFormat, maxLength))
 	content = append(content, "")
 
-	// Add previous commit messages if available
-	if len(previousCommits) > 0 {
-		content = append(content, fmt.Sprintf(PromptPreviousCommitsFormat, formatPreviousCommits(previousCommits)))
+	for _, section := range sections {
+		content = append(content, section)
 		content = append(content, "")
 	}
 
@@ -68,22 +199,105 @@ func GenerateUserPromptWithPreviousCommits(t commit.Type, maxLength int, diff st
 	return strings.Join(content, "\n")
 }
 
-func GenerateCommitMessage(ctx context.Context, provider AIPrompt, commitType commit.Type, diff string, candidateCount int) ([]string, error) {
-	systemPrompt := GenerateSystemPrompt(commitType)
-	userPrompt := GenerateUserPrompt(commitType, commit.DefaultMaxLength, diff)
-	return provider.Generate(ctx, systemPrompt, userPrompt, candidateCount)
+func GenerateCommitMessage(ctx context.Context, provider AIPrompt, commitType commit.Type, lang, diff string, candidateCount int, extraContext ...string) ([]string, error) {
+	systemPrompt := GenerateSystemPrompt(commitType, lang)
+	userPrompt := GenerateUserPrompt(commitType, commit.DefaultMaxLength, diff, extraContext...)
+	return provider.Generate(withCommitRequest(ctx, commitType, diff), systemPrompt, userPrompt, candidateCount)
 }
 
 func GenerateCommitMessageWithPreviousCommits(
 	ctx context.Context,
 	provider AIPrompt,
 	commitType commit.Type,
+	lang,
 	workDir,
 	diff string,
 	previousCommits []string,
 	candidateCount int,
+	extraContext ...string,
+) ([]string, error) {
+	systemPrompt := GenerateSystemPrompt(commitType, lang)
+	userPrompt := GenerateUserPromptWithPreviousCommits(commitType, commit.DefaultMaxLength, diff, previousCommits, extraContext...)
+	return provider.Generate(withCommitRequest(ctx, commitType, diff), systemPrompt, userPrompt, candidateCount)
+}
+
+// GenerateAmendCommitMessage generates commit messages for a commit that is
+// being amended, using its existing message as context.
+func GenerateAmendCommitMessage(
+	ctx context.Context,
+	provider AIPrompt,
+	commitType commit.Type,
+	lang,
+	diff,
+	currentMessage string,
+	previousCommits []string,
+	candidateCount int,
+	extraContext ...string,
 ) ([]string, error) {
-	systemPrompt := GenerateSystemPrompt(commitType)
-	userPrompt := GenerateUserPromptWithPreviousCommits(commitType, commit.DefaultMaxLength, diff, previousCommits)
-	return provider.Generate(ctx, systemPrompt, userPrompt, candidateCount)
+	systemPrompt := GenerateSystemPrompt(commitType, lang)
+	userPrompt := GenerateAmendUserPrompt(commitType, commit.DefaultMaxLength, diff, currentMessage, previousCommits, extraContext...)
+	return provider.Generate(withCommitRequest(ctx, commitType, diff), systemPrompt, userPrompt, candidateCount)
+}
+
+func GenerateRewordCommitMessage(
+	ctx context.Context,
+	provider AIPrompt,
+	commitType commit.Type,
+	lang,
+	diff,
+	currentMessage string,
+	siblingCommits []string,
+	candidateCount int,
+) ([]string, error) {
+	systemPrompt := GenerateSystemPrompt(commitType, lang)
+	userPrompt := GenerateRewordUserPrompt(commitType, commit.DefaultMaxLength, diff, currentMessage, siblingCommits)
+	return provider.Generate(withCommitRequest(ctx, commitType, diff), systemPrompt, userPrompt, candidateCount)
+}
+
+// GenerateRepairUserPrompt creates the user prompt asking the model to fix the
+// rule violations in a previously generated commit message.
+func GenerateRepairUserPrompt(t commit.Type, maxLength int, diff, message string, violations []commit.Violation) string {
+	var rules []string
+	for _, v := range violations {
+		rules = append(rules, "- "+v.String())
+	}
+
+	var content []string
+	content = append(content, fmt.Sprintf(PromptRepairFormat, strings.Join(rules, "\n"), message))
+	if ct := commitType(t); ct != "" {
+		content = append(content, ct)
+		content = append(content, "")
+	}
+	content = append(content, PromptDetails)
+	content = append(content, fmt.Sprintf(PromptMaxLengthFormat, maxLength))
+	content = append(content, "")
+	content = append(content, fmt.Sprintf(PromptCodeDiffFormat, diff))
+	return strings.Join(content, "\n")
+}
+
+// RepairCommitMessage re-prompts the model with the rule violations found in
+// a generated commit message and returns the rewritten message.
+func RepairCommitMessage(
+	ctx context.Context,
+	provider AIPrompt,
+	commitType commit.Type,
+	lang string,
+	maxLength int,
+	diff,
+	message string,
+	violations []commit.Violation,
+) (string, error) {
+	systemPrompt := GenerateSystemPrompt(commitType, lang)
+	userPrompt := GenerateRepairUserPrompt(commitType, maxLength, diff, message, violations)
+
+	messages, err := provider.Generate(ctx, systemPrompt, userPrompt, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if len(messages) == 0 {
+		return "", errors.New("no commit message was generated")
+	}
+
+	return messages[0], nil
 }
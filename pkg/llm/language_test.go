@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zbiljic/kai/pkg/commit"
+)
+
+func TestLanguagePrompts(t *testing.T) {
+	if prompt := GenerateSystemPrompt(commit.ConventionalType, ""); strings.Contains(prompt, "Write the commit message in") {
+		t.Errorf("Expected no language instruction without a language, got:\n%s", prompt)
+	}
+
+	prompt := GenerateSystemPrompt(commit.ConventionalType, "German")
+	if !strings.HasPrefix(prompt, GenerateSystemPrompt(commit.ConventionalType, "")) {
+		t.Errorf("Expected the language instruction after the system prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Write the commit message in German, but keep the type, the scope") {
+		t.Errorf("Expected the language instruction, got:\n%s", prompt)
+	}
+
+	prPrompt, err := prGenSystemPrompt(false, true, "Japanese")
+	if err != nil {
+		t.Fatalf("prGenSystemPrompt returned error: %v", err)
+	}
+	if !strings.Contains(prPrompt, "Write the PR title and description in Japanese.") || !strings.Contains(prPrompt, "headings of a provided PR template, unchanged") {
+		t.Errorf("Expected the PR language instruction, got:\n%s", prPrompt)
+	}
+
+	prpPrompt, err := prpGenSystemPrompt("German")
+	if err != nil {
+		t.Fatalf("prpGenSystemPrompt returned error: %v", err)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(prpPrompt), "Keep the conventional commit types, the scopes, the hunk IDs and the JSON keys unchanged.") {
+		t.Errorf("Expected the prprepare language instruction, got:\n%s", prpPrompt)
+	}
+
+	if got := bodySystemPrompt("German"); got != PromptBodySystem+" Write the text in German." {
+		t.Errorf("Expected the body language instruction, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GenerateMergeUserPrompt generates the user prompt for the body of a merge
+// commit message, from the subjects of the merged commits.
+func GenerateMergeUserPrompt(header string, subjects []string) string {
+	return fmt.Sprintf(PromptMergeFormat, header, formatPreviousCommits(subjects))
+}
+
+// GenerateMergeCommitBody generates candidates for the body of a merge commit
+// message, summarizing the merged commits.
+func GenerateMergeCommitBody(ctx context.Context, provider AIPrompt, lang, header string, subjects []string, candidateCount int) ([]string, error) {
+	bodies, err := provider.Generate(ctx, bodySystemPrompt(lang), GenerateMergeUserPrompt(header, subjects), candidateCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return trimmedResponses(bodies, "no merge commit message was generated")
+}
+
+// GenerateRevertUserPrompt generates the user prompt explaining why a commit
+// is reverted, from the context given by the user.
+func GenerateRevertUserPrompt(revertedMessage, reason string) string {
+	return fmt.Sprintf(PromptRevertFormat, reason, revertedMessage)
+}
+
+// GenerateRevertReason generates a short explanation of why the commit is
+// reverted, from the context given by the user.
+func GenerateRevertReason(ctx context.Context, provider AIPrompt, lang, revertedMessage, reason string) (string, error) {
+	reasons, err := provider.Generate(ctx, bodySystemPrompt(lang), GenerateRevertUserPrompt(revertedMessage, reason), 1)
+	if err != nil {
+		return "", err
+	}
+
+	reasons, err = trimmedResponses(reasons, "no revert reason was generated")
+	if err != nil {
+		return "", err
+	}
+
+	return reasons[0], nil
+}
+
+// bodySystemPrompt returns the system prompt for the texts added to commit
+// messages, written in the language if one is given.
+func bodySystemPrompt(lang string) string {
+	if lang == "" {
+		return PromptBodySystem
+	}
+	return PromptBodySystem + " " + fmt.Sprintf(PromptBodyLanguageFormat, lang)
+}
+
+// trimmedResponses trims the responses and removes the empty ones.
+func trimmedResponses(responses []string, emptyErr string) ([]string, error) {
+	var trimmed []string
+	for _, response := range responses {
+		if response = strings.TrimSpace(response); response != "" {
+			trimmed = append(trimmed, response)
+		}
+	}
+
+	if len(trimmed) == 0 {
+		return nil, errors.New(emptyErr)
+	}
+
+	return trimmed, nil
+}
@@ -11,6 +11,8 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+
+	"github.com/zbiljic/kai/pkg/gitdiff"
 )
 
 //go:embed templates/pr/*
@@ -71,8 +73,9 @@ func loadTemplates() (*Templates, error) {
 	return templates, nil
 }
 
-// prGenSystemPrompt generates system prompt for PR generation.
-func prGenSystemPrompt(withContext, withTemplate bool) (string, error) {
+// prGenSystemPrompt generates system prompt for PR generation. With a
+// language, the title and description are written in it.
+func prGenSystemPrompt(withContext, withTemplate bool, lang string) (string, error) {
 	tmpl, err := loadTemplates()
 	if err != nil {
 		return "", fmt.Errorf("failed to load templates: %w", err)
@@ -96,10 +99,22 @@ func prGenSystemPrompt(withContext, withTemplate bool) (string, error) {
 		prompt.WriteString(tmpl.stringTemplates["system_prompt_no_template"])
 	}
 
+	if lang != "" {
+		languageTmpl, ok := tmpl.goTemplates["system_prompt_language"]
+		if !ok {
+			return "", fmt.Errorf("system_prompt_language template not found")
+		}
+
+		prompt.WriteString("\n")
+		if err := languageTmpl.Execute(&prompt, map[string]any{"Language": lang}); err != nil {
+			return "", fmt.Errorf("failed to execute language template: %w", err)
+		}
+	}
+
 	return prompt.String(), nil
 }
 
-func prGenUserPrompt(diff, prTemplate string) (string, error) {
+func prGenUserPrompt(diff, changeSummary, excludedFiles, prTemplate string) (string, error) {
 	tmpl, err := loadTemplates()
 	if err != nil {
 		return "", fmt.Errorf("failed to load templates: %w", err)
@@ -112,8 +127,10 @@ func prGenUserPrompt(diff, prTemplate string) (string, error) {
 	}
 
 	err = userPromptTmpl.Execute(&userPromptBuf, map[string]any{
-		"PRGuidelines": tmpl.stringTemplates["pr_guidelines"],
-		"Diff":         diff,
+		"PRGuidelines":  tmpl.stringTemplates["pr_guidelines"],
+		"ChangeSummary": changeSummary,
+		"Diff":          diff,
+		"ExcludedFiles": excludedFiles,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to execute user prompt template: %w", err)
@@ -154,17 +171,20 @@ func GeneratePRContent(
 	commits,
 	diff,
 	context,
-	prTemplate string,
+	prTemplate,
+	lang string,
+	fileChanges []*gitdiff.FileChange,
+	excluded []gitdiff.FileStat,
 	maxDiffSize int,
 ) (string, string, error) {
 	// Create system prompt
-	systemPrompt, err := prGenSystemPrompt(context != "", prTemplate != "")
+	systemPrompt, err := prGenSystemPrompt(context != "", prTemplate != "", lang)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate system prompt: %w", err)
 	}
 
 	// Create user prompt
-	userPrompt, err := prGenUserPrompt(diff, prTemplate)
+	userPrompt, err := prGenUserPrompt(diff, ChangeSummarySection(fileChanges), ExcludedFilesSection(excluded), prTemplate)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate user prompt: %w", err)
 	}
@@ -2,6 +2,8 @@ package llm
 
 import (
 	"context"
+
+	"github.com/zbiljic/kai/pkg/commit"
 )
 
 // AIPrompt is an interface for generating prompts from input data.
@@ -19,3 +21,54 @@ type AIPrompt interface {
 	// The candidateCount parameter determines how many message candidates to generate.
 	Generate(ctx context.Context, systemPrompt, userPrompt string, candidateCount int) ([]string, error)
 }
+
+// Usage is the number of tokens used by the requests made by a provider.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add adds the token counts of a request to the usage.
+func (u *Usage) Add(promptTokens, completionTokens int) {
+	u.PromptTokens += promptTokens
+	u.CompletionTokens += completionTokens
+	u.TotalTokens += promptTokens + completionTokens
+}
+
+// ProviderInfo is implemented by providers which describe the model they use
+// and report the token usage of their requests.
+type ProviderInfo interface {
+	// Name returns the name of the provider, without the model.
+	Name() string
+
+	// Model returns the model used by the provider.
+	Model() string
+
+	// Usage returns the number of tokens used by all requests so far.
+	Usage() Usage
+}
+
+// CommitRequest is the commit message requested with the prompts, for the
+// providers which generate it without them, e.g. the offline heuristics.
+type CommitRequest struct {
+	// Type is the commit type of the message.
+	Type commit.Type
+
+	// Diff is the diff the message describes.
+	Diff string
+}
+
+type commitRequestKey struct{}
+
+// withCommitRequest returns the context of a request for a commit message.
+func withCommitRequest(ctx context.Context, t commit.Type, diff string) context.Context {
+	return context.WithValue(ctx, commitRequestKey{}, CommitRequest{Type: t, Diff: diff})
+}
+
+// CommitRequestFromContext returns the commit message requested with the
+// prompts of the context, if they request one.
+func CommitRequestFromContext(ctx context.Context) (CommitRequest, bool) {
+	r, ok := ctx.Value(commitRequestKey{}).(CommitRequest)
+	return r, ok
+}
@@ -18,7 +18,10 @@ const (
 )
 
 // Compile-time proof of interface implementation.
-var _ llm.AIPrompt = (*Claude)(nil)
+var (
+	_ llm.AIPrompt     = (*Claude)(nil)
+	_ llm.ProviderInfo = (*Claude)(nil)
+)
 
 type ClaudeOptions struct {
 	ApiKey  string
@@ -29,6 +32,7 @@ type ClaudeOptions struct {
 type Claude struct {
 	options ClaudeOptions
 	client  *anthropic.Client
+	usage   llm.Usage
 }
 
 func NewClaudeProvider(opts ...ClaudeOptions) (llm.AIPrompt, error) {
@@ -70,6 +74,18 @@ func (c *Claude) String() string {
 	return fmt.Sprintf("Claude (%s)", c.options.Model)
 }
 
+func (c *Claude) Name() string {
+	return "Claude"
+}
+
+func (c *Claude) Model() string {
+	return c.options.Model
+}
+
+func (c *Claude) Usage() llm.Usage {
+	return c.usage
+}
+
 func (c *Claude) IsAvailable() bool {
 	return os.Getenv("ANTHROPIC_API_KEY") != ""
 }
@@ -97,6 +113,8 @@ func (c *Claude) Generate(ctx context.Context, systemPrompt, userPrompt string,
 			return nil, fmt.Errorf("failed to generate content: %w", err)
 		}
 
+		c.usage.Add(int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens))
+
 		if len(resp.Content) == 0 {
 			return nil, errors.New("no completion choice available")
 		}
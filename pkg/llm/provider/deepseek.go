@@ -19,7 +19,10 @@ const (
 )
 
 // Compile-time proof of interface implementation.
-var _ llm.AIPrompt = (*DeepSeek)(nil)
+var (
+	_ llm.AIPrompt     = (*DeepSeek)(nil)
+	_ llm.ProviderInfo = (*DeepSeek)(nil)
+)
 
 type DeepSeekOptions struct {
 	ApiKey  string
@@ -29,6 +32,7 @@ type DeepSeekOptions struct {
 
 type DeepSeek struct {
 	options DeepSeekOptions
+	usage   llm.Usage
 }
 
 func NewDeepSeekProvider(opts ...DeepSeekOptions) llm.AIPrompt {
@@ -58,6 +62,18 @@ func (d *DeepSeek) String() string {
 	return fmt.Sprintf("DeepSeek (%s)", d.options.Model)
 }
 
+func (d *DeepSeek) Name() string {
+	return "DeepSeek"
+}
+
+func (d *DeepSeek) Model() string {
+	return d.options.Model
+}
+
+func (d *DeepSeek) Usage() llm.Usage {
+	return d.usage
+}
+
 func (d *DeepSeek) IsAvailable() bool {
 	return os.Getenv("DEEPSEEK_API_KEY") != ""
 }
@@ -111,6 +127,8 @@ func (d *DeepSeek) Generate(ctx context.Context, systemPrompt, userPrompt string
 		return nil, errors.New(respError.Error.Message)
 	}
 
+	d.usage.Add(respContent.Usage.PromptTokens, respContent.Usage.CompletionTokens)
+
 	if len(respContent.Choices) == 0 {
 		return nil, errors.New("no completion choice available")
 	}
@@ -18,7 +18,10 @@ const (
 )
 
 // Compile-time proof of interface implementation.
-var _ llm.AIPrompt = (*GoogleAI)(nil)
+var (
+	_ llm.AIPrompt     = (*GoogleAI)(nil)
+	_ llm.ProviderInfo = (*GoogleAI)(nil)
+)
 
 // GoogleAIOptions holds configuration for the GoogleAI provider.
 type GoogleAIOptions struct {
@@ -30,6 +33,7 @@ type GoogleAIOptions struct {
 type GoogleAI struct {
 	options GoogleAIOptions
 	client  *genai.Client
+	usage   llm.Usage
 }
 
 // NewGoogleAIProvider creates a new GoogleAI provider instance.
@@ -71,6 +75,18 @@ func (o *GoogleAI) String() string {
 	return fmt.Sprintf("GoogleAI (%s)", o.options.Model)
 }
 
+func (o *GoogleAI) Name() string {
+	return "GoogleAI"
+}
+
+func (o *GoogleAI) Model() string {
+	return o.options.Model
+}
+
+func (o *GoogleAI) Usage() llm.Usage {
+	return o.usage
+}
+
 func (o *GoogleAI) IsAvailable() bool {
 	return os.Getenv("GEMINI_API_KEY") != ""
 }
@@ -98,6 +114,10 @@ func (p *GoogleAI) Generate(ctx context.Context, systemPrompt, userPrompt string
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
 
+	if resp != nil && resp.UsageMetadata != nil {
+		p.usage.Add(int(resp.UsageMetadata.PromptTokenCount), int(resp.UsageMetadata.CandidatesTokenCount))
+	}
+
 	if resp == nil || len(resp.Candidates) == 0 {
 		if resp != nil && resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockedReasonUnspecified {
 			return nil, fmt.Errorf("prompt blocked due to: %s. Safety Ratings: %+v", resp.PromptFeedback.BlockReason, resp.PromptFeedback.SafetyRatings)
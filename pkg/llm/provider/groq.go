@@ -19,7 +19,10 @@ const (
 )
 
 // Compile-time proof of interface implementation.
-var _ llm.AIPrompt = (*Groq)(nil)
+var (
+	_ llm.AIPrompt     = (*Groq)(nil)
+	_ llm.ProviderInfo = (*Groq)(nil)
+)
 
 type GroqOptions struct {
 	ApiKey  string
@@ -29,6 +32,7 @@ type GroqOptions struct {
 
 type Groq struct {
 	options GroqOptions
+	usage   llm.Usage
 }
 
 func NewGroqProvider(opts ...GroqOptions) llm.AIPrompt {
@@ -58,6 +62,18 @@ func (g *Groq) String() string {
 	return fmt.Sprintf("Groq (%s)", g.options.Model)
 }
 
+func (g *Groq) Name() string {
+	return "Groq"
+}
+
+func (g *Groq) Model() string {
+	return g.options.Model
+}
+
+func (g *Groq) Usage() llm.Usage {
+	return g.usage
+}
+
 func (g *Groq) IsAvailable() bool {
 	return os.Getenv("GROQ_API_KEY") != ""
 }
@@ -120,6 +136,8 @@ func (g *Groq) Generate(ctx context.Context, systemPrompt, userPrompt string, ca
 			return nil, fmt.Errorf("Groq API error: %s", respError.Error.Message)
 		}
 
+		g.usage.Add(respContent.Usage.PromptTokens, respContent.Usage.CompletionTokens)
+
 		if len(respContent.Choices) == 0 {
 			return nil, errors.New("no completion choice available from Groq")
 		}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zbiljic/kai/pkg/heuristic"
+	"github.com/zbiljic/kai/pkg/llm"
+)
+
+// Compile-time proof of interface implementation.
+var (
+	_ llm.AIPrompt     = (*Offline)(nil)
+	_ llm.ProviderInfo = (*Offline)(nil)
+)
+
+// Offline generates commit messages with the rule-based heuristics of the
+// heuristic package, without any network requests. Only the commit message
+// requests are supported, which pass the commit type and the diff with the
+// context, other prompts return an error.
+type Offline struct{}
+
+func NewOfflineProvider() llm.AIPrompt {
+	return &Offline{}
+}
+
+func (p *Offline) String() string {
+	return "offline heuristics"
+}
+
+func (p *Offline) Name() string {
+	return "Offline"
+}
+
+func (p *Offline) Model() string {
+	return "heuristic"
+}
+
+func (p *Offline) Usage() llm.Usage {
+	return llm.Usage{}
+}
+
+func (p *Offline) IsAvailable() bool {
+	return true
+}
+
+func (p *Offline) Generate(ctx context.Context, _, _ string, _ int) ([]string, error) {
+	// the language of the prompts is ignored, the heuristics only write in
+	// English
+	r, ok := llm.CommitRequestFromContext(ctx)
+	if !ok {
+		return nil, errors.New("the offline provider only generates commit messages")
+	}
+
+	message := heuristic.Message(r.Diff)
+	if message.CommitMessage == "" {
+		return nil, errors.New("no changes to describe")
+	}
+
+	// the heuristics are deterministic, so there is a single candidate
+	return []string{heuristic.Render(message, r.Type)}, nil
+}
@@ -19,7 +19,10 @@ const (
 )
 
 // Compile-time proof of interface implementation.
-var _ llm.AIPrompt = (*OpenAI)(nil)
+var (
+	_ llm.AIPrompt     = (*OpenAI)(nil)
+	_ llm.ProviderInfo = (*OpenAI)(nil)
+)
 
 type OpenAIOptions struct {
 	ApiKey  string
@@ -29,6 +32,7 @@ type OpenAIOptions struct {
 
 type OpenAI struct {
 	options OpenAIOptions
+	usage   llm.Usage
 }
 
 func NewOpenAIProvider(opts ...OpenAIOptions) llm.AIPrompt {
@@ -58,6 +62,18 @@ func (o *OpenAI) String() string {
 	return fmt.Sprintf("OpenAI (%s)", o.options.Model)
 }
 
+func (o *OpenAI) Name() string {
+	return "OpenAI"
+}
+
+func (o *OpenAI) Model() string {
+	return o.options.Model
+}
+
+func (o *OpenAI) Usage() llm.Usage {
+	return o.usage
+}
+
 func (o *OpenAI) IsAvailable() bool {
 	return os.Getenv("OPENAI_API_KEY") != ""
 }
@@ -111,6 +127,8 @@ func (p *OpenAI) Generate(ctx context.Context, systemPrompt, userPrompt string,
 		return nil, errors.New(respError.Error.Message)
 	}
 
+	p.usage.Add(respContent.Usage.PromptTokens, respContent.Usage.CompletionTokens)
+
 	if len(respContent.Choices) == 0 {
 		return nil, errors.New("no completion choice available")
 	}
@@ -19,7 +19,10 @@ const (
 )
 
 // Compile-time proof of interface implementation.
-var _ llm.AIPrompt = (*OpenRouter)(nil)
+var (
+	_ llm.AIPrompt     = (*OpenRouter)(nil)
+	_ llm.ProviderInfo = (*OpenRouter)(nil)
+)
 
 type OpenRouterOptions struct {
 	ApiKey  string
@@ -29,6 +32,7 @@ type OpenRouterOptions struct {
 
 type OpenRouter struct {
 	options OpenRouterOptions
+	usage   llm.Usage
 }
 
 func NewOpenRouterProvider(opts ...OpenRouterOptions) llm.AIPrompt {
@@ -58,6 +62,18 @@ func (o *OpenRouter) String() string {
 	return fmt.Sprintf("OpenRouter (%s)", o.options.Model)
 }
 
+func (o *OpenRouter) Name() string {
+	return "OpenRouter"
+}
+
+func (o *OpenRouter) Model() string {
+	return o.options.Model
+}
+
+func (o *OpenRouter) Usage() llm.Usage {
+	return o.usage
+}
+
 func (o *OpenRouter) IsAvailable() bool {
 	return os.Getenv("OPENROUTER_API_KEY") != ""
 }
@@ -123,6 +139,8 @@ func (p *OpenRouter) Generate(ctx context.Context, systemPrompt, userPrompt stri
 		return nil, fmt.Errorf("OpenRouter API error: %s", respError.Error.Message)
 	}
 
+	p.usage.Add(respContent.Usage.PromptTokens, respContent.Usage.CompletionTokens)
+
 	if len(respContent.Choices) == 0 {
 		return nil, errors.New("no completion choice available from OpenRouter")
 	}
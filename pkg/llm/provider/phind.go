@@ -18,7 +18,10 @@ const (
 )
 
 // Compile-time proof of interface implementation.
-var _ llm.AIPrompt = (*Phind)(nil)
+var (
+	_ llm.AIPrompt     = (*Phind)(nil)
+	_ llm.ProviderInfo = (*Phind)(nil)
+)
 
 type PhindOptions struct {
 	BaseURL string
@@ -27,6 +30,7 @@ type PhindOptions struct {
 
 type Phind struct {
 	options PhindOptions
+	usage   llm.Usage
 }
 
 func NewPhindProvider(opts ...PhindOptions) llm.AIPrompt {
@@ -52,6 +56,18 @@ func (p *Phind) String() string {
 	return fmt.Sprintf("Phind (%s)", p.options.Model)
 }
 
+func (p *Phind) Name() string {
+	return "Phind"
+}
+
+func (p *Phind) Model() string {
+	return p.options.Model
+}
+
+func (p *Phind) Usage() llm.Usage {
+	return p.usage
+}
+
 func (p *Phind) IsAvailable() bool {
 	return true
 }
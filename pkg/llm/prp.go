@@ -30,6 +30,7 @@ type CommitPlan struct {
 type PlannedCommit struct {
 	Message   string   `json:"message"`
 	HunkIDs   []string `json:"hunk_ids"`
+	Files     []string `json:"files,omitempty"` // paths of the files without hunks
 	Rationale string   `json:"rationale"`
 }
 
@@ -81,8 +82,9 @@ func loadPrpTemplates() (*PrpTemplates, error) {
 	return templates, nil
 }
 
-// prpGenSystemPrompt generates system prompt for commit reorganization
-func prpGenSystemPrompt() (string, error) {
+// prpGenSystemPrompt generates system prompt for commit reorganization. With a
+// language, the commit messages and rationales are written in it.
+func prpGenSystemPrompt(lang string) (string, error) {
 	tmpl, err := loadPrpTemplates()
 	if err != nil {
 		return "", fmt.Errorf("failed to load prp templates: %w", err)
@@ -93,11 +95,26 @@ func prpGenSystemPrompt() (string, error) {
 		return "", fmt.Errorf("system_prompt template not found")
 	}
 
-	return systemPrompt, nil
+	if lang == "" {
+		return systemPrompt, nil
+	}
+
+	languageTmpl, ok := tmpl.goTemplates["system_prompt_language"]
+	if !ok {
+		return "", fmt.Errorf("system_prompt_language template not found")
+	}
+
+	var prompt bytes.Buffer
+	prompt.WriteString(systemPrompt)
+	if err := languageTmpl.Execute(&prompt, map[string]any{"Language": lang}); err != nil {
+		return "", fmt.Errorf("failed to execute prp language template: %w", err)
+	}
+
+	return prompt.String(), nil
 }
 
 // prpGenUserPrompt generates user prompt for commit reorganization
-func prpGenUserPrompt(hunks []*gitdiff.Hunk, currentBranch, baseBranch string) (string, error) {
+func prpGenUserPrompt(hunks []*gitdiff.Hunk, files, fileChanges []*gitdiff.FileChange, currentBranch, baseBranch string) (string, error) {
 	tmpl, err := loadPrpTemplates()
 	if err != nil {
 		return "", fmt.Errorf("failed to load prp templates: %w", err)
@@ -112,7 +129,9 @@ func prpGenUserPrompt(hunks []*gitdiff.Hunk, currentBranch, baseBranch string) (
 	err = userPromptTmpl.Execute(&userPromptBuf, map[string]any{
 		"CurrentBranch": currentBranch,
 		"BaseBranch":    baseBranch,
+		"ChangeSummary": ChangeSummarySection(fileChanges),
 		"Hunks":         hunks,
+		"Files":         files,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to execute prp user prompt template: %w", err)
@@ -160,22 +179,26 @@ func extractJSONFromResponse(response string) string {
 }
 
 // GenerateCommitPlan uses AI to analyze hunks and generate a structured commit
-// plan.
+// plan. The files without hunks, like pure renames, mode changes and binary
+// files, are planned by their paths.
 func GenerateCommitPlan(
 	ctx context.Context,
 	aip AIPrompt,
 	hunks []*gitdiff.Hunk,
+	files []*gitdiff.FileChange,
+	fileChanges []*gitdiff.FileChange,
 	currentBranch,
-	baseBranch string,
+	baseBranch,
+	lang string,
 ) (*CommitPlan, error) {
 	// Build system prompt
-	systemPrompt, err := prpGenSystemPrompt()
+	systemPrompt, err := prpGenSystemPrompt(lang)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate system prompt: %w", err)
 	}
 
 	// Build user prompt with hunk information
-	userPrompt, err := prpGenUserPrompt(hunks, currentBranch, baseBranch)
+	userPrompt, err := prpGenUserPrompt(hunks, files, fileChanges, currentBranch, baseBranch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate user prompt: %w", err)
 	}
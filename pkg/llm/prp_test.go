@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zbiljic/kai/pkg/gitdiff"
+)
+
+func TestPrpGenUserPromptFiles(t *testing.T) {
+	hunks := []*gitdiff.Hunk{{ID: "main.go:1-2", FilePath: "main.go", StartLine: 1, EndLine: 2, ChangeType: "modification", Content: "+package main"}}
+	files := []*gitdiff.FileChange{{Path: "run.sh", Status: "M", OldMode: "100644", NewMode: "100755"}}
+
+	prompt, err := prpGenUserPrompt(hunks, files, nil, "feature", "main")
+	if err != nil {
+		t.Fatalf("prpGenUserPrompt returned error: %v", err)
+	}
+
+	if !strings.Contains(prompt, "Hunk ID: main.go:1-2") {
+		t.Errorf("Expected the hunk in the prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Files without line changes:\n\nFile: run.sh\nFile change: M run.sh mode 100644 → 100755") {
+		t.Errorf("Expected the file without hunks in the prompt, got:\n%s", prompt)
+	}
+
+	prompt, err = prpGenUserPrompt(hunks, nil, nil, "feature", "main")
+	if err != nil {
+		t.Fatalf("prpGenUserPrompt returned error: %v", err)
+	}
+	if strings.Contains(prompt, "Files without line changes") {
+		t.Errorf("Expected no files section without such files, got:\n%s", prompt)
+	}
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConflictResolution is the resolution of a conflict proposed by the model.
+type ConflictResolution struct {
+	// Resolution replaces the conflict region, including its markers.
+	Resolution string
+	// Explanation describes how the conflict was resolved.
+	Explanation string
+}
+
+// GenerateConflictResolutionUserPrompt generates the user prompt asking for
+// the resolution of a conflict, with the code around it as context.
+func GenerateConflictResolutionUserPrompt(path, conflict, before, after string) string {
+	return fmt.Sprintf(PromptResolveFormat, path, before, conflict, after, "```", "```")
+}
+
+// ResolveConflict asks the model to resolve a conflict of the file, and
+// explain the resolution.
+func ResolveConflict(ctx context.Context, provider AIPrompt, path, conflict, before, after string) (*ConflictResolution, error) {
+	userPrompt := GenerateConflictResolutionUserPrompt(path, conflict, before, after)
+
+	responses, err := provider.Generate(ctx, PromptResolveSystem, userPrompt, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(responses) == 0 {
+		return nil, errors.New("no conflict resolution was generated")
+	}
+
+	return parseConflictResolution(responses[0])
+}
+
+// parseConflictResolution parses the explanation and the resolved lines from
+// the response of the model.
+func parseConflictResolution(response string) (*ConflictResolution, error) {
+	explanationPart, resolutionPart, ok := strings.Cut(response, "Resolution:")
+	if !ok {
+		return nil, errors.New("the conflict resolution doesn't contain a resolution")
+	}
+
+	explanation := strings.TrimSpace(explanationPart)
+	if _, after, ok := strings.Cut(explanation, "Explanation:"); ok {
+		explanation = strings.TrimSpace(after)
+	}
+
+	lines := strings.Split(strings.Trim(resolutionPart, "\n"), "\n")
+
+	// the resolved lines are in a code block, which may have a language
+	start, end := -1, -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if start == -1 {
+				start = i
+			} else {
+				end = i
+			}
+		}
+	}
+
+	if start != -1 && end != -1 {
+		lines = lines[start+1 : end]
+	}
+
+	resolution := strings.Join(lines, "\n")
+	if strings.Contains(resolution, "<<<<<<<") || strings.Contains(resolution, ">>>>>>>") {
+		return nil, errors.New("the conflict resolution still contains conflict markers")
+	}
+
+	return &ConflictResolution{
+		Resolution:  resolution,
+		Explanation: explanation,
+	}, nil
+}
@@ -0,0 +1,24 @@
+package llm
+
+import "testing"
+
+func TestParseConflictResolution(t *testing.T) {
+	response := "Explanation: Keeps the new argument from both sides.\nResolution:\n```go\n\trun(name)\n\n\texit()\n```\n"
+
+	resolution, err := parseConflictResolution(response)
+	if err != nil {
+		t.Fatalf("Failed to parse resolution: %s", err)
+	}
+
+	if resolution.Explanation != "Keeps the new argument from both sides." {
+		t.Errorf("Unexpected explanation %q", resolution.Explanation)
+	}
+
+	if resolution.Resolution != "\trun(name)\n\n\texit()" {
+		t.Errorf("Unexpected resolution %q", resolution.Resolution)
+	}
+
+	if _, err := parseConflictResolution("Resolution:\n```\n<<<<<<< HEAD\n```"); err == nil {
+		t.Error("Expected error for resolution with conflict markers")
+	}
+}
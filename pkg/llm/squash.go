@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zbiljic/kai/pkg/commit"
+)
+
+// GenerateSquashUserPrompt generates the user prompt for the message of a
+// squash-merged branch, from the commits and the diff of the branch. The diff
+// is truncated to maxDiffSize characters.
+func GenerateSquashUserPrompt(t commit.Type, maxLength int, commits, diff string, maxDiffSize int) string {
+	if maxDiffSize > 0 && len(diff) > maxDiffSize {
+		diff = diff[:maxDiffSize] + "\n... (diff truncated)"
+	}
+
+	var content []string
+	content = append(content, PromptSquashIntro)
+	content = append(content, "")
+	if ct := commitType(t); ct != "" {
+		content = append(content, ct)
+		content = append(content, "")
+	}
+	content = append(content, PromptSquashDetails)
+	content = append(content, fmt.Sprintf(PromptSquashMaxLengthFormat, maxLength))
+	content = append(content, "")
+	content = append(content, fmt.Sprintf(PromptSquashCommitsFormat, commits))
+	content = append(content, fmt.Sprintf(PromptCodeDiffFormat, diff))
+	return strings.Join(content, "\n")
+}
+
+// GenerateSquashCommitMessage generates a single commit message, a subject and
+// a body, summarizing all the commits of a branch.
+func GenerateSquashCommitMessage(
+	ctx context.Context,
+	provider AIPrompt,
+	commitType commit.Type,
+	lang,
+	commits,
+	diff string,
+	maxDiffSize int,
+) (string, error) {
+	systemPrompt := GenerateSystemPrompt(commitType, lang)
+	userPrompt := GenerateSquashUserPrompt(commitType, commit.DefaultMaxLength, commits, diff, maxDiffSize)
+
+	messages, err := provider.Generate(withCommitRequest(ctx, commitType, diff), systemPrompt, userPrompt, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if len(messages) == 0 || strings.TrimSpace(messages[0]) == "" {
+		return "", errors.New("no squash commit message was generated")
+	}
+
+	return strings.TrimSpace(messages[0]), nil
+}
@@ -17,11 +17,17 @@ func mapToString(m map[string]string) string {
 
 // mapToTable formats a map as a markdown table with two columns: Type and Description
 func mapToTable(m map[string]string) string {
+	return mapToTableWithHeader(m, "Type")
+}
+
+// mapToTableWithHeader formats a map as a markdown table with two columns: the
+// given key header and Description
+func mapToTableWithHeader(m map[string]string, keyHeader string) string {
 	var sb strings.Builder
 
 	// Add table header
-	sb.WriteString("| Type | Description |\n")
-	sb.WriteString("| ---- | ----------- |\n")
+	fmt.Fprintf(&sb, "| %s | Description |\n", keyHeader)
+	fmt.Fprintf(&sb, "| %s | ----------- |\n", strings.Repeat("-", len(keyHeader)))
 
 	// Get sorted keys for consistent output
 	keys := make([]string, 0, len(m))
@@ -117,3 +117,12 @@ func TestMapToTable(t *testing.T) {
 		})
 	}
 }
+
+func TestMapToTableWithHeader(t *testing.T) {
+	result := mapToTableWithHeader(map[string]string{"api": "Public API"}, "Scope")
+
+	expected := "| Scope | Description |\n| ----- | ----------- |\n| api | Public API |\n"
+	if result != expected {
+		t.Errorf("Expected %q, but got %q", expected, result)
+	}
+}
@@ -0,0 +1,54 @@
+package promptsx
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Editor returns the editor command from $GIT_EDITOR, $VISUAL or $EDITOR, in
+// that order, falling back to vi.
+func Editor() string {
+	for _, env := range []string{"GIT_EDITOR", "VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// EditInEditor opens the content in the user's editor and returns the edited
+// content. The pattern names the temporary file (as in os.CreateTemp), so the
+// editor can detect the file type.
+func EditInEditor(content, pattern string) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// the editor may contain arguments, run it through the shell like git does
+	cmd := exec.Command("sh", "-c", Editor()+` "$@"`, Editor(), file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+
+	return string(edited), nil
+}
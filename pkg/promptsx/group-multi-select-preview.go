@@ -0,0 +1,150 @@
+package promptsx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/orochaa/go-clack/core"
+	"github.com/orochaa/go-clack/core/validator"
+	"github.com/orochaa/go-clack/prompts"
+	"github.com/orochaa/go-clack/prompts/symbols"
+	"github.com/orochaa/go-clack/prompts/theme"
+	"github.com/orochaa/go-clack/third_party/picocolors"
+)
+
+type GroupMultiSelectPreviewParams[TValue comparable] struct {
+	Message string
+	// Groups is the order in which the groups are displayed.
+	Groups  []string
+	Options map[string][]prompts.MultiSelectOption[TValue]
+	// Preview returns the preview shown below the list for the active option.
+	Preview func(value TValue) string
+	// PreviewLines is the maximum number of preview lines (default: 10).
+	PreviewLines int
+	Required     bool
+}
+
+// GroupMultiSelectPreview displays a grouped multi select prompt, with a
+// preview of the active option below the options. Groups are displayed in the
+// given order, and selecting a group selects all of its options.
+func GroupMultiSelectPreview[TValue comparable](params GroupMultiSelectPreviewParams[TValue]) ([]TValue, error) {
+	v := validator.NewValidator("GroupMultiSelectPreview")
+	v.ValidateOptions(len(params.Options))
+
+	if params.PreviewLines <= 0 {
+		params.PreviewLines = 10
+	}
+
+	groups := make(map[string][]core.MultiSelectOption[TValue])
+	for group, options := range params.Options {
+		groups[group] = make([]core.MultiSelectOption[TValue], len(options))
+		for i, option := range options {
+			groups[group][i] = core.MultiSelectOption[TValue]{
+				Label:      option.Label,
+				Value:      option.Value,
+				Hint:       option.Hint,
+				IsSelected: option.IsSelected,
+			}
+		}
+	}
+
+	p := core.NewGroupMultiSelectPrompt(core.GroupMultiSelectPromptParams[TValue]{
+		Options:  groups,
+		Required: params.Required,
+		Render: func(p *core.GroupMultiSelectPrompt[TValue]) string {
+			var value string
+
+			switch p.State {
+			case core.SubmitState, core.CancelState:
+				selected := 0
+				for _, option := range p.Options {
+					if !option.IsGroup && option.IsSelected {
+						selected++
+					}
+				}
+				value = fmt.Sprintf("%d selected", selected)
+			default:
+				var preview []string
+				if params.Preview != nil && p.CursorIndex >= 0 && p.CursorIndex < len(p.Options) && !p.Options[p.CursorIndex].IsGroup {
+					preview = strings.Split(strings.TrimRight(params.Preview(p.Options[p.CursorIndex].Value), "\n"), "\n")
+					if len(preview) > params.PreviewLines {
+						preview = append(preview[:params.PreviewLines], picocolors.Dim("..."))
+					}
+				}
+
+				lines := make([]string, len(p.Options))
+				for i, option := range p.Options {
+					if option.IsGroup {
+						lines[i] = groupMultiSelectOption(option, p.IsGroupSelected(option), i == p.CursorIndex)
+						continue
+					}
+					lines[i] = " " + groupMultiSelectOption(option, option.IsSelected, i == p.CursorIndex)
+				}
+
+				value = p.LimitLines(lines, 4+params.PreviewLines)
+				if len(preview) > 0 {
+					value += "\r\n\r\n" + strings.Join(preview, "\r\n")
+				}
+			}
+
+			return theme.ApplyTheme(theme.ThemeParams[[]TValue]{
+				Context:         p.Prompt,
+				Message:         params.Message,
+				Value:           value,
+				ValueWithCursor: value,
+			})
+		},
+	})
+
+	// the options are created from a map, order them as requested and restore
+	// the hints which aren't copied
+	byLabel := make(map[string]*core.GroupMultiSelectOption[TValue])
+	for _, option := range p.Options {
+		if option.IsGroup {
+			byLabel[option.Label] = option
+		}
+	}
+
+	ordered := make([]*core.GroupMultiSelectOption[TValue], 0, len(p.Options))
+	for _, name := range params.Groups {
+		group, ok := byLabel[name]
+		if !ok {
+			continue
+		}
+		ordered = append(ordered, group)
+		for i, option := range group.Options {
+			option.Hint = params.Options[name][i].Hint
+			ordered = append(ordered, option)
+		}
+	}
+	if len(ordered) == len(p.Options) {
+		p.Options = ordered
+	}
+
+	return p.Run()
+}
+
+func groupMultiSelectOption[TValue comparable](option *core.GroupMultiSelectOption[TValue], isSelected, isActive bool) string {
+	var radio, label, hint string
+
+	switch {
+	case isActive:
+		radio = picocolors.Green(symbols.CHECKBOX_ACTIVE)
+		if isSelected {
+			radio = picocolors.Green(symbols.CHECKBOX_SELECTED)
+		}
+		label = option.Label
+	case isSelected:
+		radio = picocolors.Green(symbols.CHECKBOX_SELECTED)
+		label = picocolors.Dim(option.Label)
+	default:
+		radio = picocolors.Dim(symbols.CHECKBOX_INACTIVE)
+		label = picocolors.Dim(option.Label)
+	}
+
+	if option.Hint != "" {
+		hint = " " + picocolors.Dim("("+option.Hint+")")
+	}
+
+	return radio + " " + label + hint
+}
@@ -9,8 +9,9 @@ import (
 )
 
 type EditableValue[TValue any] struct {
-	Value TValue
-	Edit  bool
+	Value  TValue
+	Edit   bool
+	Editor bool // edit the value in the user's editor, see EditInEditor
 }
 
 type SelectEditOption[TValue any] struct {
@@ -22,16 +23,18 @@ type SelectEditOption[TValue any] struct {
 
 type SelectEditPrompt[TValue any] struct {
 	core.Prompt[EditableValue[TValue]]
-	Options []*SelectEditOption[TValue]
-	EditKey core.KeyName
+	Options   []*SelectEditOption[TValue]
+	EditKey   core.KeyName
+	EditorKey core.KeyName
 }
 
 type SelectEditPromptParams[TValue any] struct {
-	Input   *os.File
-	Output  *os.File
-	Options []*SelectEditOption[TValue]
-	EditKey core.KeyName
-	Render  func(p *SelectEditPrompt[TValue]) string
+	Input     *os.File
+	Output    *os.File
+	Options   []*SelectEditOption[TValue]
+	EditKey   core.KeyName
+	EditorKey core.KeyName
+	Render    func(p *SelectEditPrompt[TValue]) string
 }
 
 func NewSelectEditPrompt[TValue any](params SelectEditPromptParams[TValue]) *SelectEditPrompt[TValue] {
@@ -50,6 +53,9 @@ func NewSelectEditPrompt[TValue any](params SelectEditPromptParams[TValue]) *Sel
 	if params.EditKey == "" {
 		params.EditKey = "e"
 	}
+	if params.EditorKey == "" {
+		params.EditorKey = "E"
+	}
 
 	var p SelectEditPrompt[TValue]
 	p = SelectEditPrompt[TValue]{
@@ -62,8 +68,9 @@ func NewSelectEditPrompt[TValue any](params SelectEditPromptParams[TValue]) *Sel
 			CursorIndex: startIndex,
 			Render:      core.WrapRender[EditableValue[TValue]](&p, params.Render),
 		}),
-		Options: params.Options,
-		EditKey: params.EditKey,
+		Options:   params.Options,
+		EditKey:   params.EditKey,
+		EditorKey: params.EditorKey,
 	}
 
 	p.On(core.KeyEvent, func(args ...any) {
@@ -87,13 +94,14 @@ func (p *SelectEditPrompt[TValue]) handleKeyPress(key *core.Key) {
 	}
 
 	switch key.Name {
-	case p.EditKey:
+	case p.EditKey, p.EditorKey:
 		for i, option := range p.Options {
 			if i == p.CursorIndex {
 				p.State = core.SubmitState
 				p.Value = EditableValue[TValue]{
-					Value: option.Value,
-					Edit:  true,
+					Value:  option.Value,
+					Edit:   key.Name == p.EditKey,
+					Editor: key.Name == p.EditorKey,
 				}
 				return
 			}
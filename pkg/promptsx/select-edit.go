@@ -11,10 +11,11 @@ import (
 )
 
 type SelectEditParams[TValue comparable] struct {
-	Message  string
-	Options  []SelectEditOption[TValue]
-	EditKey  core.KeyName
-	EditHint string
+	Message   string
+	Options   []SelectEditOption[TValue]
+	EditKey   core.KeyName
+	EditorKey core.KeyName
+	EditHint  string
 }
 
 func SelectEdit[TValue comparable](params SelectEditParams[TValue]) (EditableValue[TValue], error) {
@@ -31,7 +32,9 @@ func SelectEdit[TValue comparable](params SelectEditParams[TValue]) (EditableVal
 	}
 
 	p := NewSelectEditPrompt(SelectEditPromptParams[TValue]{
-		Options: options,
+		Options:   options,
+		EditKey:   params.EditKey,
+		EditorKey: params.EditorKey,
 		Render: func(p *SelectEditPrompt[TValue]) string {
 			var value string
 
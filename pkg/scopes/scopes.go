@@ -0,0 +1,233 @@
+// Package scopes learns the commit scopes of a repository, from the messages
+// of its history and from rules mapping paths to scopes, and suggests the
+// scopes matching the changed files.
+package scopes
+
+import (
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zbiljic/kai/pkg/commit"
+	"github.com/zbiljic/kai/pkg/gitdiff"
+	"github.com/zbiljic/kai/pkg/history"
+)
+
+// MaxSuggestions is the maximum number of suggested scopes.
+const MaxSuggestions = 10
+
+// Rule maps the paths matching a gitignore-syntax pattern to a scope, e.g.
+// "services/billing/" to "billing" in a monorepo.
+type Rule struct {
+	Path  string `json:"path"`
+	Scope string `json:"scope"`
+}
+
+// Usage is how often a scope is used in the history, and the directories of
+// the files changed by the commits using it.
+type Usage struct {
+	Count       int            `json:"count"`
+	Directories map[string]int `json:"directories"`
+}
+
+// Vocabulary is the frequency table of the scopes of the history.
+type Vocabulary map[string]*Usage
+
+// Learn builds the vocabulary from the messages of the commits, parsed with
+// commit.ParseMessage. Commits without a scope are skipped.
+func Learn(commits []history.Commit) Vocabulary {
+	vocabulary := make(Vocabulary)
+
+	for _, c := range commits {
+		scope := strings.TrimSpace(commit.ParseMessage(c.Subject).Scope)
+		if scope == "" {
+			continue
+		}
+
+		usage, ok := vocabulary[scope]
+		if !ok {
+			usage = &Usage{Directories: make(map[string]int)}
+			vocabulary[scope] = usage
+		}
+
+		usage.Count++
+		for _, file := range c.Files {
+			usage.Directories[path.Dir(file)]++
+		}
+	}
+
+	return vocabulary
+}
+
+// Suggestion is a scope suggested for the changed files.
+type Suggestion struct {
+	Scope string  `json:"scope"`
+	Rule  string  `json:"rule,omitempty"`  // pattern of the matching rule
+	Count int     `json:"count,omitempty"` // commits of the history using the scope
+	Score float64 `json:"score"`
+}
+
+// Hint describes where the suggestion comes from, e.g. "matches services/api/"
+// or "12 commits".
+func (s Suggestion) Hint() string {
+	var hints []string
+	if s.Rule != "" {
+		hints = append(hints, "matches "+s.Rule)
+	}
+	switch {
+	case s.Count == 1:
+		hints = append(hints, "1 commit")
+	case s.Count > 1:
+		hints = append(hints, strconv.Itoa(s.Count)+" commits")
+	}
+	return strings.Join(hints, ", ")
+}
+
+// Suggest returns the scopes for the changed files, most relevant first: the
+// scopes of the rules matching the files, then the scopes of the history used
+// near the files, then the rest of the history by frequency. Scopes used only
+// once, away from the files, are left out as likely typos.
+func Suggest(vocabulary Vocabulary, rules []Rule, files []string) []Suggestion {
+	byScope := make(map[string]*Suggestion)
+	var suggestions []*Suggestion
+
+	suggestion := func(scope string) *Suggestion {
+		s, ok := byScope[scope]
+		if !ok {
+			s = &Suggestion{Scope: scope}
+			byScope[scope] = s
+			suggestions = append(suggestions, s)
+		}
+		return s
+	}
+
+	for _, rule := range rules {
+		if rule.Path == "" || rule.Scope == "" {
+			continue
+		}
+
+		ignore := gitdiff.NewIgnore(rule.Path)
+
+		matched := 0
+		for _, file := range files {
+			if ignore.Match(file) {
+				matched++
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+
+		s := suggestion(rule.Scope)
+		if s.Rule == "" {
+			s.Rule = rule.Path
+		}
+		// a rule outweighs any history
+		s.Score = max(s.Score, 1+float64(matched)/float64(len(files)))
+	}
+
+	for scope, usage := range vocabulary {
+		relevance := usage.relevance(files)
+		if relevance == 0 && usage.Count < 2 && byScope[scope] == nil {
+			continue
+		}
+
+		s := suggestion(scope)
+		s.Count = usage.Count
+		if s.Rule == "" {
+			s.Score = relevance
+		}
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b *Suggestion) int {
+		switch {
+		case a.Score != b.Score:
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		case a.Count != b.Count:
+			return b.Count - a.Count
+		default:
+			return strings.Compare(a.Scope, b.Scope)
+		}
+	})
+
+	result := make([]Suggestion, 0, min(len(suggestions), MaxSuggestions))
+	for _, s := range suggestions {
+		if len(result) == MaxSuggestions {
+			break
+		}
+		result = append(result, *s)
+	}
+
+	return result
+}
+
+// Matched returns the suggestions from the rules matching the changed files.
+func Matched(suggestions []Suggestion) []Suggestion {
+	var matched []Suggestion
+	for _, s := range suggestions {
+		if s.Rule != "" {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+// relevance returns the share of the changed files in, or near, the
+// directories touched by the commits using the scope, weighted by how often
+// the directories were touched. It is between 0 and 1.
+func (u *Usage) relevance(files []string) float64 {
+	if len(files) == 0 {
+		return 0
+	}
+
+	// summed in a stable order, so equal scores stay equal between runs
+	directories := make([]string, 0, len(u.Directories))
+	for directory := range u.Directories {
+		directories = append(directories, directory)
+	}
+	slices.Sort(directories)
+
+	var total float64
+	for _, file := range files {
+		dir := splitDir(path.Dir(file))
+
+		var weighted, touches float64
+		for _, directory := range directories {
+			count := float64(u.Directories[directory])
+			weighted += count * proximity(dir, splitDir(directory))
+			touches += count
+		}
+		if touches > 0 {
+			total += weighted / touches
+		}
+	}
+
+	return total / float64(len(files))
+}
+
+// proximity returns 1 for the same directory, less for a common parent
+// directory, and 0 without one.
+func proximity(a, b []string) float64 {
+	depth := max(len(a), len(b))
+	if depth == 0 {
+		return 1
+	}
+
+	common := 0
+	for common < len(a) && common < len(b) && a[common] == b[common] {
+		common++
+	}
+
+	return float64(common) / float64(depth)
+}
+
+func splitDir(dir string) []string {
+	if dir == "." {
+		return nil
+	}
+	return strings.Split(dir, "/")
+}
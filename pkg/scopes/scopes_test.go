@@ -0,0 +1,63 @@
+package scopes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zbiljic/kai/pkg/history"
+)
+
+func TestLearn(t *testing.T) {
+	vocabulary := Learn([]history.Commit{
+		{Subject: "feat(api): add users endpoint", Files: []string{"api/users.go", "api/router.go"}},
+		{Subject: "fix(api)!: validate ids", Files: []string{"api/users.go"}},
+		{Subject: ":bug: (web) fix layout", Files: []string{"web/app.ts"}},
+		{Subject: "docs: update readme", Files: []string{"README.md"}},
+	})
+
+	expected := Vocabulary{
+		"api": {Count: 2, Directories: map[string]int{"api": 3}},
+		"web": {Count: 1, Directories: map[string]int{"web": 1}},
+	}
+	if !reflect.DeepEqual(vocabulary, expected) {
+		t.Errorf("Expected %v, got %v", expected, vocabulary)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	vocabulary := Vocabulary{
+		"api":    {Count: 5, Directories: map[string]int{"services/api": 5}},
+		"web":    {Count: 8, Directories: map[string]int{"web": 8}},
+		"ci":     {Count: 3, Directories: map[string]int{".github/workflows": 3}},
+		"backnd": {Count: 1, Directories: map[string]int{"tools/backend": 1}},
+	}
+	rules := []Rule{
+		{Path: "services/billing/", Scope: "billing"},
+		{Path: "services/api/", Scope: "api"},
+	}
+
+	suggestions := Suggest(vocabulary, rules, []string{"services/billing/invoice.go"})
+
+	var got []string
+	for _, s := range suggestions {
+		got = append(got, s.Scope)
+	}
+
+	// the matching rule first, then the history near the files, then the
+	// rest by frequency, without the one-off scope away from the files
+	expected := []string{"billing", "api", "web", "ci"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v (%+v)", expected, got, suggestions)
+	}
+
+	if hint := suggestions[0].Hint(); hint != "matches services/billing/" {
+		t.Errorf("Unexpected hint %q", hint)
+	}
+	if hint := suggestions[1].Hint(); hint != "5 commits" {
+		t.Errorf("Unexpected hint %q", hint)
+	}
+
+	if matched := Matched(suggestions); len(matched) != 1 || matched[0].Scope != "billing" {
+		t.Errorf("Expected only the billing rule to match, got %+v", matched)
+	}
+}